@@ -0,0 +1,24 @@
+package cache
+
+import "context"
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, m *Manager) context.Context {
+	return context.WithValue(ctx, contextKey{}, m)
+}
+
+func FromContext(ctx context.Context) (*Manager, bool) {
+	m, ok := ctx.Value(contextKey{}).(*Manager)
+	return m, ok
+}
+
+// RepositoryFromContext returns the repository of the name from the manager in the context.
+func RepositoryFromContext(ctx context.Context, names ...string) (Repository, error) {
+	m, ok := FromContext(ctx)
+	if !ok {
+		return nil, ErrManagerNotFound
+	}
+
+	return m.Resolve(names...)
+}
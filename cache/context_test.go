@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	m1, ok1 := FromContext(context.Background())
+	assert.False(t, ok1)
+	assert.Nil(t, m1)
+
+	_, err := RepositoryFromContext(context.Background())
+	assert.ErrorIs(t, err, ErrManagerNotFound)
+
+	m := NewManagerFromConfig(&Config{
+		Default: "null",
+		Stores: map[string]StoreConfig{
+			"null": {Driver: "null"},
+		},
+	})
+	ctx := NewContext(context.Background(), m)
+
+	m2, ok2 := FromContext(ctx)
+	assert.True(t, ok2)
+	assert.Equal(t, m, m2)
+
+	r, err := RepositoryFromContext(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, r)
+}
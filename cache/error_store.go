@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// errorStore fails all the operations with the error, such as the tagged store of a store without tagging.
+type errorStore struct {
+	err    error
+	prefix string
+}
+
+var (
+	_ Store    = (*errorStore)(nil)
+	_ Addable  = (*errorStore)(nil)
+	_ Bulkable = (*errorStore)(nil)
+)
+
+func (s *errorStore) Has(context.Context, string) (bool, error) {
+	return false, s.err
+}
+
+func (s *errorStore) Get(context.Context, string, any) error {
+	return s.err
+}
+
+func (s *errorStore) Put(context.Context, string, any, time.Duration) (bool, error) {
+	return false, s.err
+}
+
+func (s *errorStore) Increment(context.Context, string, int) (int, error) {
+	return 0, s.err
+}
+
+func (s *errorStore) Decrement(context.Context, string, int) (int, error) {
+	return 0, s.err
+}
+
+func (s *errorStore) Forever(context.Context, string, any) (bool, error) {
+	return false, s.err
+}
+
+func (s *errorStore) Forget(context.Context, string) (bool, error) {
+	return false, s.err
+}
+
+func (s *errorStore) Flush(context.Context) (bool, error) {
+	return false, s.err
+}
+
+func (s *errorStore) GetPrefix() string {
+	return s.prefix
+}
+
+func (s *errorStore) Add(context.Context, string, any, time.Duration) (bool, error) {
+	return false, s.err
+}
+
+func (s *errorStore) Many(context.Context, []string, any) error {
+	return s.err
+}
+
+func (s *errorStore) PutMany(context.Context, map[string]any, time.Duration) (bool, error) {
+	return false, s.err
+}
+
+func (s *errorStore) ForgetMany(context.Context, ...string) (bool, error) {
+	return false, s.err
+}
+
+func (s *errorStore) Lock(string, time.Duration) locker.Locker {
+	return &errorLocker{err: s.err}
+}
+
+type errorLocker struct {
+	err error
+}
+
+var _ locker.Locker = (*errorLocker)(nil)
+
+func (l *errorLocker) Try(context.Context, func()) error {
+	return l.err
+}
+
+func (l *errorLocker) Until(context.Context, time.Duration, func()) error {
+	return l.err
+}
+
+func (l *errorLocker) Get(context.Context) (locker.Owner, error) {
+	return nil, l.err
+}
+
+func (l *errorLocker) Release(context.Context, locker.Owner) error {
+	return l.err
+}
+
+func (l *errorLocker) ForceRelease(context.Context) error {
+	return l.err
+}
+
+func (l *errorLocker) LockedOwner(context.Context) (locker.Owner, error) {
+	return nil, l.err
+}
@@ -0,0 +1,45 @@
+package cache
+
+import "time"
+
+// CacheHit is dispatched when the key is found in the cache.
+type CacheHit struct {
+	Store string
+	Key   string
+}
+
+func (*CacheHit) Event() any {
+	return CacheHit{}
+}
+
+// CacheMissed is dispatched when the key is not found in the cache.
+type CacheMissed struct {
+	Store string
+	Key   string
+}
+
+func (*CacheMissed) Event() any {
+	return CacheMissed{}
+}
+
+// KeyWritten is dispatched when the key is written into the cache.
+// The TTL is 0 if the key is stored forever, or the ttl is kept, e.g. by Increment and Decrement.
+type KeyWritten struct {
+	Store string
+	Key   string
+	TTL   time.Duration
+}
+
+func (*KeyWritten) Event() any {
+	return KeyWritten{}
+}
+
+// KeyForgotten is dispatched when the key is removed from the cache.
+type KeyForgotten struct {
+	Store string
+	Key   string
+}
+
+func (*KeyForgotten) Event() any {
+	return KeyForgotten{}
+}
@@ -0,0 +1,141 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/cache/memory"
+	"github.com/go-kratos-ecosystem/components/v2/event"
+)
+
+type cacheListener struct {
+	events []event.Event
+	mu     sync.Mutex
+}
+
+func (l *cacheListener) Listen() []event.Event {
+	return []event.Event{
+		&cache.CacheHit{},
+		&cache.CacheMissed{},
+		&cache.KeyWritten{},
+		&cache.KeyForgotten{},
+	}
+}
+
+func (l *cacheListener) Handle(e event.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, e)
+}
+
+func TestRepository_Events(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	defer store.Close()
+
+	listener := &cacheListener{}
+	dispatcher := event.NewDispatcher()
+	dispatcher.AddListener(listener)
+
+	repo := cache.NewRepository(store, cache.WithName("memory"), cache.WithDispatcher(dispatcher))
+
+	var v string
+	assert.ErrorIs(t, repo.Get(ctx, "events", &v), cache.ErrNotFound)
+	_, _ = repo.Set(ctx, "events", "value", time.Minute)
+	assert.NoError(t, repo.Get(ctx, "events", &v))
+	_, _ = repo.Delete(ctx, "events")
+	_, _ = repo.Delete(ctx, "events") // not found, no event
+
+	assert.Equal(t, []event.Event{
+		&cache.CacheMissed{Store: "memory", Key: "events"},
+		&cache.KeyWritten{Store: "memory", Key: "events", TTL: time.Minute},
+		&cache.CacheHit{Store: "memory", Key: "events"},
+		&cache.KeyForgotten{Store: "memory", Key: "events"},
+	}, listener.events)
+
+	// bulk operations
+	listener.events = nil
+	_, _ = repo.PutMany(ctx, map[string]any{"events:many": 1}, 0)
+	// the keys already in dest are not hits
+	values := map[string]int{"events:none": 2}
+	assert.NoError(t, repo.Many(ctx, []string{"events:many", "events:none"}, values))
+	assert.Equal(t, map[string]int{"events:many": 1, "events:none": 2}, values)
+
+	assert.Equal(t, []event.Event{
+		&cache.KeyWritten{Store: "memory", Key: "events:many"},
+		&cache.CacheHit{Store: "memory", Key: "events:many"},
+		&cache.CacheMissed{Store: "memory", Key: "events:none"},
+	}, listener.events)
+
+	// increments and decrements
+	listener.events = nil
+	_, _ = repo.Increment(ctx, "events:counter", 2)
+	_, _ = repo.Decrement(ctx, "events:counter", 1)
+
+	assert.Equal(t, []event.Event{
+		&cache.KeyWritten{Store: "memory", Key: "events:counter"},
+		&cache.KeyWritten{Store: "memory", Key: "events:counter"},
+	}, listener.events)
+}
+
+func TestRepository_Telemetry(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	defer store.Close()
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	repo := cache.NewRepository(store,
+		cache.WithName("memory"),
+		cache.WithMeterProvider(mp),
+		cache.WithTracerProvider(tp),
+	)
+
+	var v string
+	assert.ErrorIs(t, repo.Get(ctx, "telemetry", &v), cache.ErrNotFound)
+	_, _ = repo.Set(ctx, "telemetry", "value", time.Minute)
+	assert.NoError(t, repo.Get(ctx, "telemetry", &v))
+
+	// spans
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "cache.get", spans[0].Name)
+	assert.Equal(t, "cache.put", spans[1].Name)
+	assert.Equal(t, "cache.get", spans[2].Name)
+
+	// metrics
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	counts := make(map[string]int64)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != "cache.operations" {
+			continue
+		}
+		for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+			op, _ := dp.Attributes.Value("cache.operation")
+			result, _ := dp.Attributes.Value("cache.result")
+			counts[op.AsString()+":"+result.AsString()] += dp.Value
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		"get:miss":    1,
+		"get:hit":     1,
+		"put:success": 1,
+	}, counts)
+}
@@ -0,0 +1,21 @@
+package file
+
+import (
+	"errors"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+)
+
+var ErrPathRequired = errors.New("cache: the path of the file store is required")
+
+// Driver returns the factory of the file store for cache.Manager.
+func Driver(opts ...Option) cache.DriverFactory {
+	return func(config cache.StoreConfig, codec codec.Codec) (cache.Store, error) {
+		if config.Path == "" {
+			return nil, ErrPathRequired
+		}
+
+		return New(config.Path, append([]Option{Prefix(config.Prefix), Codec(codec)}, opts...)...), nil
+	}
+}
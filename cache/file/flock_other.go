@@ -0,0 +1,37 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package file
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// staleTimeout is the age after which the lock file is considered abandoned.
+const staleTimeout = 10 * time.Second
+
+// lockFile acquires the lock by creating the file exclusively, it blocks until the lock is acquired.
+// The unlock always removes the file.
+func lockFile(path string) (func(remove bool) error, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0o600) //nolint:mnd
+		if err == nil {
+			_ = f.Close()
+			return func(bool) error {
+				return os.Remove(path)
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleTimeout {
+			_ = os.Remove(path)
+			continue
+		}
+
+		time.Sleep(time.Millisecond * 5) //nolint:mnd
+	}
+}
@@ -0,0 +1,63 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package file
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// lockFile acquires the exclusive flock of the file, it blocks until the lock is acquired.
+// The unlock removes the file if remove is true, which is safe since the lock is only held
+// when the file at the path is still the locked one.
+func lockFile(path string) (func(remove bool) error, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600) //nolint:mnd
+		if err != nil {
+			return nil, err
+		}
+
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+
+		// the file may be removed by the previous holder while waiting
+		if same, err := locked(f, path); err != nil {
+			_ = f.Close()
+			return nil, err
+		} else if !same {
+			_ = f.Close()
+			continue
+		}
+
+		return func(remove bool) error {
+			defer f.Close()
+			if remove {
+				if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+			}
+			return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		}, nil
+	}
+}
+
+// locked reports whether the file at the path is the opened file.
+func locked(f *os.File, path string) (bool, error) {
+	opened, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	current, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return os.SameFile(opened, current), nil
+}
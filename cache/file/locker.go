@@ -0,0 +1,131 @@
+package file
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// Locker is a file based locker, which is shared by the processes using the same directory.
+// The lock file records the owner and the expiration time of the lock.
+type Locker struct {
+	store *Store
+	path  string
+	ttl   time.Duration
+	sleep time.Duration // for Until
+}
+
+var _ locker.Locker = (*Locker)(nil)
+
+func newLocker(store *Store, path string, ttl time.Duration) *Locker {
+	return &Locker{
+		store: store,
+		path:  path,
+		ttl:   ttl,
+		sleep: time.Millisecond * 50, //nolint:mnd
+	}
+}
+
+func (l *Locker) Try(ctx context.Context, fn func()) error {
+	owner, err := l.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (l *Locker) Until(ctx context.Context, timeout time.Duration, fn func()) error {
+	starting := time.Now()
+	owner := locker.NewOwner(l)
+
+	for {
+		if ok, err := l.acquire(owner); err != nil {
+			return err
+		} else if ok {
+			break
+		}
+
+		if time.Since(starting) >= timeout {
+			return locker.ErrTimeout
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(l.sleep):
+		}
+	}
+
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (l *Locker) Get(context.Context) (locker.Owner, error) {
+	owner := locker.NewOwner(l)
+	ok, err := l.acquire(owner)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, locker.ErrLocked
+	}
+	return owner, nil
+}
+
+func (l *Locker) Release(_ context.Context, owner locker.Owner) error {
+	return l.store.guard(l.path, func() error {
+		_, name, ok, err := l.store.read(l.path)
+		if err != nil {
+			return err
+		}
+		if !ok || string(name) != owner.Name() {
+			return locker.ErrNotLocked
+		}
+
+		return l.store.remove(l.path)
+	})
+}
+
+func (l *Locker) ForceRelease(context.Context) error {
+	return l.store.guard(l.path, func() error {
+		return l.store.remove(l.path)
+	})
+}
+
+func (l *Locker) LockedOwner(context.Context) (locker.Owner, error) {
+	_, name, ok, err := l.store.read(l.path)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, locker.ErrNotLocked
+	}
+
+	return locker.NewOwner(l, locker.WithOwnerName(string(name))), nil
+}
+
+func (l *Locker) acquire(owner locker.Owner) (bool, error) {
+	var acquired bool
+	err := l.store.guard(l.path, func() error {
+		if _, _, ok, err := l.store.read(l.path); err != nil || ok {
+			return err
+		}
+
+		acquired = true
+		return l.store.write(l.path, []byte(owner.Name()), expiration(l.ttl))
+	})
+
+	return acquired, err
+}
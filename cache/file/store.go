@@ -0,0 +1,381 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+	"github.com/go-kratos-ecosystem/components/v2/codec/json"
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// locksDir is the directory of the lock files, which is kept by Flush.
+const locksDir = "locks"
+
+// itemLocksDir is the directory of the lock files of the items in locksDir,
+// which are out of the shard directories, so the locks being held are not removed by Flush.
+const itemLocksDir = "items"
+
+// Store is a file system cache store.
+//
+// Each item is stored in its own file, which is sharded into the directories
+// by the hash of the key. The first line of the file is the expiration time
+// in unix nanoseconds (0 means forever), and the rest is the encoded value.
+type Store struct {
+	dir  string
+	opts *options
+}
+
+type options struct {
+	prefix string
+	codec  codec.Codec
+}
+
+type Option func(*options)
+
+func Prefix(prefix string) Option {
+	return func(o *options) {
+		if prefix != "" {
+			o.prefix = prefix + ":"
+		}
+	}
+}
+
+func Codec(codec codec.Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+var (
+	_ cache.Store   = (*Store)(nil)
+	_ cache.Addable = (*Store)(nil)
+)
+
+func New(dir string, opts ...Option) *Store {
+	opt := &options{
+		codec: json.Codec,
+	}
+
+	for _, o := range opts {
+		o(opt)
+	}
+
+	return &Store{
+		dir:  dir,
+		opts: opt,
+	}
+}
+
+func (s *Store) Has(_ context.Context, key string) (bool, error) {
+	_, _, ok, err := s.read(s.path(key))
+	return ok, err
+}
+
+func (s *Store) Get(_ context.Context, key string, dest any) error {
+	_, value, ok, err := s.read(s.path(key))
+	if err != nil {
+		return err
+	} else if !ok {
+		return cache.ErrNotFound
+	}
+
+	return s.opts.codec.Unmarshal(value, dest)
+}
+
+func (s *Store) Put(_ context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	valued, err := s.opts.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.write(s.path(key), valued, expiration(ttl)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *Store) Increment(ctx context.Context, key string, value int) (int, error) {
+	return s.incrBy(ctx, key, value)
+}
+
+func (s *Store) Decrement(ctx context.Context, key string, value int) (int, error) {
+	return s.incrBy(ctx, key, -value)
+}
+
+func (s *Store) Forever(_ context.Context, key string, value any) (bool, error) {
+	valued, err := s.opts.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	if err := s.write(s.path(key), valued, time.Time{}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *Store) Forget(_ context.Context, key string) (bool, error) {
+	path := s.path(key)
+
+	var ok bool
+	err := s.guard(path, func() (err error) {
+		if _, _, ok, err = s.read(path); err != nil {
+			return err
+		}
+		return s.remove(path)
+	})
+
+	return ok, err
+}
+
+// Flush removes all the items in the directory of the store, the locks are kept.
+// Only the shard directories of the store are removed, so the other files in the directory are kept.
+func (s *Store) Flush(context.Context) (bool, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	// the shards are moved out first, so the concurrent writes do not fill them while removing
+	trash, err := os.MkdirTemp(s.dir, ".flush-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.RemoveAll(trash) //nolint:errcheck
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !shard(entry.Name()) {
+			continue
+		}
+
+		path := filepath.Join(s.dir, entry.Name())
+		if err := os.Rename(path, filepath.Join(trash, entry.Name())); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			// e.g. the directory with the opened files can not be renamed on windows
+			if err := os.RemoveAll(path); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return true, os.RemoveAll(trash)
+}
+
+func (s *Store) GetPrefix() string {
+	return s.opts.prefix
+}
+
+func (s *Store) Add(_ context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	valued, err := s.opts.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	path := s.path(key)
+
+	var added bool
+	err = s.guard(path, func() error {
+		if _, _, ok, err := s.read(path); err != nil || ok {
+			return err
+		}
+
+		added = true
+		return s.write(path, valued, expiration(ttl))
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return added, nil
+}
+
+func (s *Store) Lock(key string, ttl time.Duration) locker.Locker {
+	return newLocker(s, s.lockPath(key), ttl)
+}
+
+func (s *Store) incrBy(_ context.Context, key string, value int) (int, error) {
+	path := s.path(key)
+
+	var current int
+	err := s.guard(path, func() error {
+		expiry, valued, ok, err := s.read(path)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if err := s.opts.codec.Unmarshal(valued, &current); err != nil {
+				return err
+			}
+		}
+
+		current += value
+
+		if valued, err = s.opts.codec.Marshal(current); err != nil {
+			return err
+		}
+		return s.write(path, valued, expiry)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return current, nil
+}
+
+// path returns the path of the item file, e.g. dir/ab/cd/abcdef...
+func (s *Store) path(key string) string {
+	hash := s.hash(key)
+	return filepath.Join(s.dir, hash[0:2], hash[2:4], hash)
+}
+
+// itemLockPath returns the path of the lock file of the item file, e.g. dir/locks/items/ab/abcdef....lock
+func (s *Store) itemLockPath(path string) string {
+	hash := filepath.Base(path)
+	return filepath.Join(s.dir, locksDir, itemLocksDir, hash[0:2], hash+".lock")
+}
+
+// lockPath returns the path of the lock file, e.g. dir/locks/abcdef...
+func (s *Store) lockPath(key string) string {
+	return filepath.Join(s.dir, locksDir, s.hash(key))
+}
+
+func (s *Store) hash(key string) string {
+	sum := sha1.Sum([]byte(s.opts.prefix + key)) //nolint:gosec
+	return hex.EncodeToString(sum[:])
+}
+
+// read reads the unexpired item file.
+// If the file does not exist or is expired, the ok will be false.
+func (s *Store) read(path string) (expiry time.Time, value []byte, ok bool, err error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return expiry, nil, false, nil
+	} else if err != nil {
+		return expiry, nil, false, err
+	}
+
+	header, value, found := bytes.Cut(content, []byte("\n"))
+	if !found {
+		return expiry, nil, false, nil
+	}
+
+	nanos, err := strconv.ParseInt(string(header), 10, 64)
+	if err != nil {
+		return expiry, nil, false, nil //nolint:nilerr // the broken file is treated as missing
+	}
+	if nanos > 0 {
+		expiry = time.Unix(0, nanos)
+		if !time.Now().Before(expiry) {
+			return expiry, nil, false, nil
+		}
+	}
+
+	return expiry, value, true, nil
+}
+
+// writeRetries is the number of the retries of writing, when the shard directory is removed by Flush.
+const writeRetries = 3
+
+// write writes the item file atomically, by writing a temporary file and renaming it.
+func (s *Store) write(path string, value []byte, expiry time.Time) (err error) {
+	for i := 0; i <= writeRetries; i++ {
+		if err = s.writeFile(path, value, expiry); !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *Store) writeFile(path string, value []byte, expiry time.Time) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:mnd
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	var nanos int64
+	if !expiry.IsZero() {
+		nanos = expiry.UnixNano()
+	}
+
+	if _, err := tmp.WriteString(strconv.FormatInt(nanos, 10) + "\n"); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(value); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *Store) remove(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// guard calls fn while holding the file lock of the path,
+// so the read-modify-write of the path is atomic across the processes.
+// The lock file is removed if the file of the path does not exist after fn,
+// the lock files of the flushed items are removed by the next guard of them.
+func (s *Store) guard(path string, fn func() error) (err error) {
+	lockPath := s.itemLockPath(path)
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o755); err != nil { //nolint:mnd
+		return err
+	}
+
+	unlock, err := lockFile(lockPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, e := os.Stat(path)
+		err = errors.Join(err, unlock(errors.Is(e, fs.ErrNotExist)))
+	}()
+
+	return fn()
+}
+
+// shard reports whether the name is a shard directory, which is two hex characters.
+func shard(name string) bool {
+	if len(name) != 2 { //nolint:mnd
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil && strings.ToLower(name) == name
+}
+
+func expiration(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
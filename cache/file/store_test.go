@@ -0,0 +1,371 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+var ctx = context.Background()
+
+func TestFile_Base(t *testing.T) {
+	store := New(t.TempDir(), Prefix("cache:file"))
+	assert.Equal(t, "cache:file:", store.GetPrefix())
+
+	ok1, err := store.Put(ctx, "test", "test", time.Millisecond*100)
+	assert.Nil(t, err)
+	assert.True(t, ok1)
+
+	var v string
+	assert.Nil(t, store.Get(ctx, "test", &v))
+	assert.Equal(t, "test", v)
+
+	ok2, err := store.Has(ctx, "test")
+	assert.Nil(t, err)
+	assert.True(t, ok2)
+
+	time.Sleep(time.Millisecond * 150)
+
+	ok3, err := store.Has(ctx, "test")
+	assert.Nil(t, err)
+	assert.False(t, ok3)
+
+	err = store.Get(ctx, "test", &v)
+	assert.True(t, errors.Is(err, cache.ErrNotFound))
+}
+
+func TestFile_Persistent(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := New(dir).Forever(ctx, "test:persistent", "test")
+	assert.Nil(t, err)
+
+	// another store with the same directory, e.g. the next run of the command
+	var v string
+	assert.Nil(t, New(dir).Get(ctx, "test:persistent", &v))
+	assert.Equal(t, "test", v)
+
+	// the prefix isolates the keys
+	ok, err := New(dir, Prefix("other")).Has(ctx, "test:persistent")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestFile_IncrAndDecr(t *testing.T) {
+	store := New(t.TempDir())
+
+	v1, err := store.Increment(ctx, "test:inc", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v1)
+
+	v2, err := store.Increment(ctx, "test:inc", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 11, v2)
+
+	v3, err := store.Decrement(ctx, "test:inc", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, v3)
+
+	// concurrent
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.Increment(ctx, "test:inc", 1)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	var v int
+	assert.Nil(t, store.Get(ctx, "test:inc", &v))
+	assert.Equal(t, 30, v)
+
+	// put another type
+	_, err = store.Put(ctx, "test:inc:type", "test", time.Second)
+	assert.Nil(t, err)
+
+	v4, err := store.Increment(ctx, "test:inc:type", 1)
+	assert.Error(t, err)
+	assert.Zero(t, v4)
+}
+
+func TestFile_ForgetAndFlush(t *testing.T) {
+	dir := t.TempDir()
+	store := New(dir)
+
+	_, err := store.Put(ctx, "test:forget", "test", time.Second)
+	assert.Nil(t, err)
+
+	ok1, err := store.Forget(ctx, "test:forget")
+	assert.Nil(t, err)
+	assert.True(t, ok1)
+
+	ok2, err := store.Forget(ctx, "test:forget")
+	assert.Nil(t, err)
+	assert.False(t, ok2)
+
+	// flush keeps the locks and the files outside the directory
+	outside := filepath.Join(filepath.Dir(dir), filepath.Base(dir)+".outside")
+	assert.Nil(t, os.WriteFile(outside, []byte("test"), 0o600))
+	t.Cleanup(func() {
+		_ = os.Remove(outside)
+	})
+
+	_, err = store.Put(ctx, "test:flush", "test", time.Second)
+	assert.Nil(t, err)
+	owner, err := store.Lock("test:flush", time.Second).Get(ctx)
+	assert.Nil(t, err)
+
+	ok3, err := store.Flush(ctx)
+	assert.Nil(t, err)
+	assert.True(t, ok3)
+
+	ok4, err := store.Has(ctx, "test:flush")
+	assert.Nil(t, err)
+	assert.False(t, ok4)
+
+	_, err = os.Stat(outside)
+	assert.Nil(t, err)
+
+	assert.Nil(t, owner.Release(ctx))
+
+	ok5, err := New(filepath.Join(dir, "none")).Flush(ctx)
+	assert.Nil(t, err)
+	assert.True(t, ok5)
+}
+
+func TestFile_Add(t *testing.T) {
+	store := New(t.TempDir())
+
+	ok1, err := store.Add(ctx, "test:add", "test", time.Millisecond*100)
+	assert.Nil(t, err)
+	assert.True(t, ok1)
+
+	ok2, err := store.Add(ctx, "test:add", "test", time.Millisecond*100)
+	assert.Nil(t, err)
+	assert.False(t, ok2)
+
+	time.Sleep(time.Millisecond * 150)
+
+	ok3, err := store.Add(ctx, "test:add", "test", time.Millisecond*100)
+	assert.Nil(t, err)
+	assert.True(t, ok3)
+}
+
+func TestFile_Lock(t *testing.T) {
+	store := New(t.TempDir())
+	var wg sync.WaitGroup
+	var s int64
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.Lock("test", 5*time.Second).Try(ctx, func() {
+				time.Sleep(time.Millisecond * 100)
+			})
+			if err != nil {
+				assert.True(t, errors.Is(err, locker.ErrLocked))
+			} else {
+				atomic.AddInt64(&s, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.True(t, s > 0)
+	assert.True(t, s < 10)
+}
+
+func TestFile_LockOwner(t *testing.T) {
+	store := New(t.TempDir())
+	l := store.Lock("test:owner", time.Millisecond*100)
+
+	owner1, err := l.Get(ctx)
+	assert.NoError(t, err)
+
+	owner, err := l.LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, owner1.Name(), owner.Name())
+
+	_, err = l.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	assert.ErrorIs(t, l.Release(ctx, locker.NewOwner(l)), locker.ErrNotLocked)
+	assert.NoError(t, owner1.Release(ctx))
+
+	// expired
+	_, err = l.Get(ctx)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 150)
+	_, err = l.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+
+	// until
+	_, err = l.Get(ctx)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, l.Until(ctx, time.Millisecond*20, func() {}), locker.ErrTimeout)
+	assert.NoError(t, l.Until(ctx, time.Millisecond*300, func() {}))
+
+	// force release
+	_, err = l.Get(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, l.ForceRelease(ctx))
+	_, err = l.Get(ctx)
+	assert.NoError(t, err)
+}
+
+func TestFile_Driver(t *testing.T) {
+	manager := cache.NewManagerFromConfig(&cache.Config{
+		Default: "file",
+		Stores: map[string]cache.StoreConfig{
+			"file":    {Driver: "file", Prefix: "cache:file", Path: t.TempDir()},
+			"invalid": {Driver: "file"},
+		},
+	}, cache.WithDriver("file", Driver()))
+
+	repo, err := manager.Resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, "cache:file:", repo.GetPrefix())
+
+	_, err = manager.Resolve("invalid")
+	assert.ErrorIs(t, err, ErrPathRequired)
+}
+
+func TestFile_FlushShared(t *testing.T) {
+	dir := t.TempDir()
+	store := New(dir)
+
+	// the files not created by the store are kept
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other"), 0o600))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "zz"), 0o755))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "app"), 0o755))
+
+	_, err := store.Put(ctx, "test", "test", 0)
+	assert.NoError(t, err)
+
+	ok, err := store.Flush(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"other.txt", "zz", "app"}, names)
+}
+
+func TestFile_LockFiles(t *testing.T) {
+	dir := t.TempDir()
+	store := New(dir)
+
+	count := func() int {
+		var n int
+		_ = filepath.WalkDir(dir, func(path string, _ os.DirEntry, _ error) error {
+			if filepath.Ext(path) == ".lock" {
+				n++
+			}
+			return nil
+		})
+		return n
+	}
+
+	_, err := store.Increment(ctx, "test", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count())
+
+	// the lock files are removed with the items
+	_, err = store.Forget(ctx, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count())
+
+	l := store.Lock("test", time.Second)
+	assert.NoError(t, l.Try(ctx, func() {}))
+	assert.Equal(t, 0, count())
+
+	// concurrent increments are still atomic
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = store.Increment(ctx, "test:concurrent", 1)
+			_, _ = store.Forget(ctx, "test:other")
+		}()
+	}
+	wg.Wait()
+
+	var v int
+	assert.NoError(t, store.Get(ctx, "test:concurrent", &v))
+	assert.Equal(t, 20, v)
+}
+
+func TestFile_FlushIncrement(t *testing.T) {
+	dir := t.TempDir()
+	store := New(dir)
+
+	var (
+		wg      sync.WaitGroup
+		inside  atomic.Int64
+		overlap atomic.Bool
+		stop    = make(chan struct{})
+		path    = store.path("test:flush:increment")
+	)
+
+	// flushing all the time
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_, err := store.Flush(ctx)
+				assert.NoError(t, err)
+			}
+		}
+	}()
+
+	// the writers of the same item never run at the same time
+	var writers sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			for j := 0; j < 20; j++ {
+				assert.NoError(t, store.guard(path, func() error {
+					if inside.Add(1) > 1 {
+						overlap.Store(true)
+					}
+					defer inside.Add(-1)
+
+					time.Sleep(time.Microsecond * 100)
+					return store.write(path, []byte("1"), time.Time{})
+				}))
+
+				_, err := store.Increment(ctx, "test:flush:increment", 1)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	writers.Wait()
+	close(stop)
+	wg.Wait()
+
+	assert.False(t, overlap.Load())
+}
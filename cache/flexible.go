@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// flexibleItem is the value stored by Flexible with its soft expiration.
+type flexibleItem[T any] struct {
+	Value T `json:"value"`
+
+	// Expiry is the soft expiration time in unix nanoseconds.
+	Expiry int64 `json:"expiry"`
+
+	// Delta is the time taken to compute the value in nanoseconds.
+	Delta int64 `json:"delta"`
+}
+
+// WithXFetch enables the probabilistic early recomputation (XFetch).
+// The value may be refreshed before the soft expiration, the earlier the larger beta is,
+// and the longer the value takes to compute. The recommended beta is 1.0.
+func WithXFetch(beta float64) RememberOption {
+	return func(o *rememberOptions) {
+		o.beta = beta
+	}
+}
+
+// WithRefreshErrorHandler sets the handler of the errors occurred while refreshing in background.
+func WithRefreshErrorHandler(handler func(key string, err error)) RememberOption {
+	return func(o *rememberOptions) {
+		o.refreshErrorHandler = handler
+	}
+}
+
+// Flexible gets the value of the key with stale-while-revalidate semantics.
+//
+// The value is fresh within the fresh duration. After that, the stale value is still served
+// for the stale duration while it is refreshed by fn in background.
+// If the key does not exist or has been stale for too long, fn is called synchronously like Remember.
+//
+// With WithRememberLock, only one process refreshes the value at a time,
+// and the others keep serving the stale value.
+//
+// Example:
+//
+//	stats, err := cache.Flexible(ctx, repo, "stats", time.Minute, time.Hour, func() (*Stats, error) {
+//		return computeStats(ctx)
+//	}, cache.WithXFetch(1))
+func Flexible[T any](
+	ctx context.Context, repo Repository, key string, fresh, stale time.Duration, fn func() (T, error),
+	opts ...RememberOption,
+) (T, error) {
+	o := &rememberOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	compute := func() (flexibleItem[T], error) {
+		starting := time.Now()
+		value, err := fn()
+		if err != nil {
+			return flexibleItem[T]{}, err
+		}
+
+		now := time.Now()
+		return flexibleItem[T]{
+			Value:  value,
+			Expiry: now.Add(fresh).UnixNano(),
+			Delta:  int64(now.Sub(starting)),
+		}, nil
+	}
+	put := func(ctx context.Context, item flexibleItem[T]) (bool, error) {
+		return repo.Put(ctx, key, item, fresh+stale)
+	}
+
+	item, ok, err := lookup[flexibleItem[T]](ctx, repo, key)
+	if err != nil {
+		return item.Value, err
+	}
+	if !ok {
+		item, err = remember(ctx, repo, key, compute, put, opts...)
+		return item.Value, err
+	}
+
+	if item.stale(time.Now(), o.beta) {
+		id := inflightOf(repo).id(repo, key, reflect.TypeFor[T]())
+		refresh(ctx, repo, key, id, o, func(ctx context.Context) error {
+			item, err := compute()
+			if err != nil {
+				return err
+			}
+
+			_, err = repo.Put(ctx, key, item, fresh+stale)
+			return err
+		})
+	}
+
+	return item.Value, nil
+}
+
+// stale reports whether the item should be refreshed.
+// With XFetch, the item is considered stale when now - delta * beta * ln(rand) >= expiry.
+func (i flexibleItem[T]) stale(now time.Time, beta float64) bool {
+	if beta <= 0 {
+		return now.UnixNano() >= i.Expiry
+	}
+
+	gap := float64(i.Delta) * beta * -math.Log(1-rand.Float64()) //nolint:gosec
+	return float64(now.UnixNano())+gap >= float64(i.Expiry)
+}
+
+// refresh calls fn in background, at most once at a time for the same id within the process.
+// With the lock option, the refresh is skipped if another process is refreshing.
+func refresh(
+	ctx context.Context, repo Repository, key, id string, o *rememberOptions, fn func(context.Context) error,
+) {
+	refreshing := &inflightOf(repo).refreshing
+	if _, loaded := refreshing.LoadOrStore(id, struct{}{}); loaded {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer refreshing.Delete(id)
+
+		err := func() error {
+			if !o.locked {
+				return fn(ctx)
+			}
+
+			owner, err := repo.Lock(key+":refresh", o.lockTTL).Get(ctx)
+			if err != nil {
+				return nil //nolint:nilerr // another process is refreshing
+			}
+			defer owner.Release(ctx) //nolint:errcheck
+
+			return fn(ctx)
+		}()
+		if err != nil && o.refreshErrorHandler != nil {
+			o.refreshErrorHandler(key, err)
+		}
+	}()
+}
@@ -0,0 +1,106 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/cache/memory"
+)
+
+func TestFlexible(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	defer store.Close()
+	repo := cache.NewRepository(store)
+
+	var calls int64
+	fn := func() (int64, error) {
+		return atomic.AddInt64(&calls, 1), nil
+	}
+
+	// miss, computed synchronously
+	v, err := cache.Flexible(ctx, repo, "flexible", time.Millisecond*100, time.Second, fn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+
+	// fresh
+	v, err = cache.Flexible(ctx, repo, "flexible", time.Millisecond*100, time.Second, fn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+
+	// stale, served and refreshed in background
+	time.Sleep(time.Millisecond * 150)
+	v, err = cache.Flexible(ctx, repo, "flexible", time.Millisecond*100, time.Second, fn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+
+	v, err = cache.Flexible(ctx, repo, "flexible", time.Millisecond*100, time.Second, fn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+
+	// expired, computed synchronously
+	time.Sleep(time.Millisecond * 1200)
+	v, err = cache.Flexible(ctx, repo, "flexible", time.Millisecond*100, time.Second, fn)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), v)
+}
+
+func TestFlexible_XFetch(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	defer store.Close()
+	repo := cache.NewRepository(store)
+
+	var calls int64
+	fn := func() (int64, error) {
+		time.Sleep(time.Millisecond * 10)
+		return atomic.AddInt64(&calls, 1), nil
+	}
+
+	_, err := cache.Flexible(ctx, repo, "flexible:xfetch", time.Minute, time.Minute, fn)
+	assert.NoError(t, err)
+
+	// a huge beta always refreshes early
+	v, err := cache.Flexible(ctx, repo, "flexible:xfetch", time.Minute, time.Minute, fn, cache.WithXFetch(1e6))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&calls))
+}
+
+func TestFlexible_RefreshError(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	defer store.Close()
+	repo := cache.NewRepository(store)
+
+	e := errors.New("failed")
+	errs := make(chan error, 1)
+
+	_, err := cache.Flexible(ctx, repo, "flexible:error", time.Millisecond, time.Minute, func() (int, error) {
+		return 1, nil
+	})
+	assert.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 10)
+	v, err := cache.Flexible(ctx, repo, "flexible:error", time.Millisecond, time.Minute, func() (int, error) {
+		return 0, e
+	}, cache.WithRefreshErrorHandler(func(key string, err error) {
+		assert.Equal(t, "flexible:error", key)
+		errs <- err
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+	assert.ErrorIs(t, <-errs, e)
+}
@@ -1,35 +1,330 @@
 package cache
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+	"github.com/go-kratos-ecosystem/components/v2/codec/json"
+	"github.com/go-kratos-ecosystem/components/v2/codec/msgpack"
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+var (
+	ErrStoreNotConfigured  = errors.New("cache: the store is not configured")
+	ErrDriverNotRegistered = errors.New("cache: the driver is not registered")
+	ErrCodecNotRegistered  = errors.New("cache: the codec is not registered")
+	ErrManagerNotFound     = errors.New("cache: the manager is not found in the context")
+)
+
+type Config struct {
+	// Default is the name of the default store.
+	Default string `json:"default"`
+
+	// Stores are the configurations of the stores, keyed by the store name.
+	Stores map[string]StoreConfig `json:"stores"`
+}
+
+type StoreConfig struct {
+	// Driver is the name of the driver, e.g. redis, memory, file and null.
+	Driver string `json:"driver"`
+
+	// Prefix is the prefix of the keys.
+	Prefix string `json:"prefix"`
+
+	// Codec is the name of the codec, e.g. json and msgpack. The default is json.
+	Codec string `json:"codec"`
+
+	// Connection is the name of the connection used by the driver, e.g. the redis connection.
+	Connection string `json:"connection"`
+
+	// Path is the directory used by the file driver.
+	Path string `json:"path"`
+}
+
+// DriverFactory creates the store with the config and the resolved codec.
+type DriverFactory func(config StoreConfig, codec codec.Codec) (Store, error)
+
+// Manager manages the repositories, which are built lazily from the config.
+// The embedded repository is the default one.
 type Manager struct {
 	Repository
 
-	drivers map[string]Repository
+	config *Config
+
+	drivers      map[string]DriverFactory
+	codecs       map[string]codec.Codec
+	repositories map[string]Repository
+	stores       map[string]Store // built by the manager, keyed by the name
+	mu           sync.Mutex
+
+	opts []Option // for the repositories
 }
 
+type ManagerOption func(*Manager)
+
+// WithDriver registers the driver factory.
+func WithDriver(name string, factory DriverFactory) ManagerOption {
+	return func(m *Manager) {
+		m.drivers[name] = factory
+	}
+}
+
+// WithCodec registers the codec.
+func WithCodec(name string, codec codec.Codec) ManagerOption {
+	return func(m *Manager) {
+		m.codecs[name] = codec
+	}
+}
+
+// WithRepositoryOptions sets the options of the repositories built by the manager.
+func WithRepositoryOptions(opts ...Option) ManagerOption {
+	return func(m *Manager) {
+		m.opts = append(m.opts, opts...)
+	}
+}
+
+// NewManager creates the manager with the default repository, the others are registered by Register.
 func NewManager(repository Repository) *Manager {
-	return &Manager{
-		Repository: repository,
-		drivers:    make(map[string]Repository),
+	m := NewManagerFromConfig(nil)
+	m.Repository = repository
+	m.Register("", repository)
+	return m
+}
+
+// NewManagerFromConfig creates the manager building the repositories from the config.
+func NewManagerFromConfig(config *Config, opts ...ManagerOption) *Manager {
+	if config == nil {
+		config = &Config{}
+	}
+
+	m := &Manager{
+		config: config,
+		drivers: map[string]DriverFactory{
+			"null": func(StoreConfig, codec.Codec) (Store, error) {
+				return &NullStore{}, nil
+			},
+		},
+		codecs: map[string]codec.Codec{
+			"json":    json.Codec,
+			"msgpack": msgpack.Codec,
+		},
+		repositories: make(map[string]Repository),
+		stores:       make(map[string]Store),
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.Repository = &defaultRepository{manager: m}
+
+	return m
 }
 
+// Register registers the pre-built repository, it takes precedence over the config.
 func (m *Manager) Register(name string, repository Repository) {
-	m.drivers[name] = repository
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.repositories[name] = repository
 }
 
+// Driver returns the repository of the name like Resolve, but it panics if the repository can not be resolved.
 func (m *Manager) Driver(names ...string) Repository {
+	r, err := m.Resolve(names...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Resolve returns the repository of the name, the default one is returned if the name is empty.
+// The repository is built from the config on the first call.
+func (m *Manager) Resolve(names ...string) (Repository, error) {
 	var name string
 	if len(names) > 0 {
 		name = names[0]
 	}
 
-	if name == "" {
-		return m.Repository
+	if name == "" && m.config.Default != "" {
+		name = m.config.Default
+	}
+
+	m.mu.Lock()
+	r, ok := m.repositories[name]
+	m.mu.Unlock()
+	if ok {
+		return r, nil
+	}
+
+	// the store is built without the lock, since the driver may be slow, e.g. dialing the redis
+	store, err := m.build(name)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// built by others at the same time
+	if r, ok := m.repositories[name]; ok {
+		if closer, ok := store.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		return r, nil
+	}
+
+	r = NewRepository(store, append([]Option{WithName(name)}, m.opts...)...)
+	m.repositories[name] = r
+	m.stores[name] = store
+
+	return r, nil
+}
+
+// Close closes the stores built by the manager which implement io.Closer.
+// The repositories of the closed stores are removed, so they are built again on the next Resolve.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var err error
+	for name, store := range m.stores {
+		if closer, ok := store.(io.Closer); ok {
+			err = errors.Join(err, closer.Close())
+		}
+		delete(m.repositories, name)
+	}
+	m.stores = make(map[string]Store)
+
+	return err
+}
+
+func (m *Manager) inflight() *inflight {
+	return inflightOf(m.Repository)
+}
+
+func (m *Manager) build(name string) (Store, error) {
+	config, ok := m.config.Stores[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrStoreNotConfigured, name)
+	}
+
+	factory, ok := m.drivers[config.Driver]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDriverNotRegistered, config.Driver)
+	}
+
+	codecName := config.Codec
+	if codecName == "" {
+		codecName = "json"
+	}
+	c, ok := m.codecs[codecName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCodecNotRegistered, codecName)
+	}
+
+	return factory(config, c)
+}
+
+// defaultRepository resolves the default repository of the manager lazily on each call,
+// the calls fail with the error if the repository can not be resolved.
+type defaultRepository struct {
+	manager *Manager
+}
+
+var _ Repository = (*defaultRepository)(nil)
+
+func (r *defaultRepository) repository() Repository {
+	repository, err := r.manager.Resolve()
+	if err != nil {
+		return NewRepository(&errorStore{err: err})
 	}
+	return repository
+}
 
-	if c, ok := m.drivers[name]; ok {
-		return c
+// inflight shares the inflight of the resolved repository, so the loads through the manager
+// and through the repository itself are deduplicated together.
+func (r *defaultRepository) inflight() *inflight {
+	repository, err := r.manager.Resolve()
+	if err != nil {
+		return defaultInflight
 	}
+	return inflightOf(repository)
+}
+
+func (r *defaultRepository) Has(ctx context.Context, key string) (bool, error) {
+	return r.repository().Has(ctx, key)
+}
+
+func (r *defaultRepository) Get(ctx context.Context, key string, dest any) error {
+	return r.repository().Get(ctx, key, dest)
+}
+
+func (r *defaultRepository) Put(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	return r.repository().Put(ctx, key, value, ttl)
+}
+
+func (r *defaultRepository) Increment(ctx context.Context, key string, value int) (int, error) {
+	return r.repository().Increment(ctx, key, value)
+}
+
+func (r *defaultRepository) Decrement(ctx context.Context, key string, value int) (int, error) {
+	return r.repository().Decrement(ctx, key, value)
+}
+
+func (r *defaultRepository) Forever(ctx context.Context, key string, value any) (bool, error) {
+	return r.repository().Forever(ctx, key, value)
+}
+
+func (r *defaultRepository) Forget(ctx context.Context, key string) (bool, error) {
+	return r.repository().Forget(ctx, key)
+}
+
+func (r *defaultRepository) Flush(ctx context.Context) (bool, error) {
+	return r.repository().Flush(ctx)
+}
+
+func (r *defaultRepository) GetPrefix() string {
+	return r.repository().GetPrefix()
+}
+
+func (r *defaultRepository) Lock(key string, ttl time.Duration) locker.Locker {
+	return r.repository().Lock(key, ttl)
+}
+
+func (r *defaultRepository) Add(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	return r.repository().Add(ctx, key, value, ttl)
+}
+
+func (r *defaultRepository) Many(ctx context.Context, keys []string, dest any) error {
+	return r.repository().Many(ctx, keys, dest)
+}
+
+func (r *defaultRepository) PutMany(ctx context.Context, values map[string]any, ttl time.Duration) (bool, error) {
+	return r.repository().PutMany(ctx, values, ttl)
+}
+
+func (r *defaultRepository) ForgetMany(ctx context.Context, keys ...string) (bool, error) {
+	return r.repository().ForgetMany(ctx, keys...)
+}
+
+func (r *defaultRepository) Missing(ctx context.Context, key string) (bool, error) {
+	return r.repository().Missing(ctx, key)
+}
+
+func (r *defaultRepository) Delete(ctx context.Context, key string) (bool, error) {
+	return r.repository().Delete(ctx, key)
+}
+
+func (r *defaultRepository) Set(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	return r.repository().Set(ctx, key, value, ttl)
+}
 
-	panic("cache: the repository [" + name + "] is not registered.")
+func (r *defaultRepository) Tags(names ...string) Repository {
+	return r.repository().Tags(names...)
 }
@@ -1,20 +1,199 @@
 package cache
 
 import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+	"github.com/go-kratos-ecosystem/components/v2/codec/msgpack"
 )
 
+type closableStore struct {
+	NullStore
+	closed bool
+}
+
+func (s *closableStore) Close() error {
+	s.closed = true
+	return nil
+}
+
 func TestManager(t *testing.T) {
+	var (
+		r1, r2 Repository
+		built  int
+		store  = &closableStore{}
+	)
+
+	manager := NewManagerFromConfig(&Config{
+		Default: "r1",
+		Stores: map[string]StoreConfig{
+			"null":    {Driver: "null"},
+			"custom":  {Driver: "custom", Prefix: "custom", Codec: "msgpack"},
+			"unknown": {Driver: "unknown"},
+			"codec":   {Driver: "null", Codec: "unknown"},
+			"failed":  {Driver: "failed"},
+		},
+	},
+		WithDriver("custom", func(config StoreConfig, c codec.Codec) (Store, error) {
+			built++
+			assert.Equal(t, "custom", config.Prefix)
+			assert.Equal(t, msgpack.Codec, c)
+			return store, nil
+		}),
+		WithDriver("failed", func(StoreConfig, codec.Codec) (Store, error) {
+			return nil, errors.New("failed")
+		}),
+	)
+	manager.Register("r1", r1)
+	manager.Register("r2", r2)
+
+	// registered
+	r, err := manager.Resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, r1, r)
+
+	r, err = manager.Resolve("r2")
+	assert.NoError(t, err)
+	assert.Equal(t, r2, r)
+
+	// built lazily
+	r, err = manager.Resolve("null")
+	assert.NoError(t, err)
+	assert.NotNil(t, r)
+
+	c1, err := manager.Resolve("custom")
+	assert.NoError(t, err)
+	c2, err := manager.Resolve("custom")
+	assert.NoError(t, err)
+	assert.Same(t, c1, c2)
+	assert.Equal(t, 1, built)
+
+	// errors
+	_, err = manager.Resolve("r3")
+	assert.ErrorIs(t, err, ErrStoreNotConfigured)
+	_, err = manager.Resolve("unknown")
+	assert.ErrorIs(t, err, ErrDriverNotRegistered)
+	_, err = manager.Resolve("codec")
+	assert.ErrorIs(t, err, ErrCodecNotRegistered)
+	_, err = manager.Resolve("failed")
+	assert.EqualError(t, err, "failed")
+
+	// close the built stores
+	assert.NoError(t, manager.Close())
+	assert.True(t, store.closed)
+
+	// the closed repositories are built again, and the registered ones are kept
+	c3, err := manager.Resolve("custom")
+	assert.NoError(t, err)
+	assert.NotSame(t, c1, c3)
+	assert.Equal(t, 2, built)
+
+	r, err = manager.Resolve("r2")
+	assert.NoError(t, err)
+	assert.Equal(t, r2, r)
+}
+
+func TestManager_Repository(t *testing.T) {
 	var r1, r2 Repository
 
 	manager := NewManager(r1)
 	manager.Register("r2", r2)
 
+	assert.Equal(t, r1, manager.Repository)
 	assert.Equal(t, r1, manager.Driver())
 	assert.Equal(t, r2, manager.Driver("r2"))
 	assert.Panics(t, func() {
 		manager.Driver("r3")
 	})
 }
+
+func TestManager_Default(t *testing.T) {
+	ctx := context.Background()
+
+	manager := NewManagerFromConfig(&Config{
+		Default: "memory",
+		Stores: map[string]StoreConfig{
+			"memory": {Driver: "memory"},
+		},
+	}, WithDriver("memory", func(StoreConfig, codec.Codec) (Store, error) {
+		return &NullStore{}, nil
+	}))
+
+	// the embedded repository is the default one
+	ok, err := manager.Put(ctx, "test", 1, time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the loads through the manager are shared with the resolved repository
+	repo, err := manager.Resolve()
+	assert.NoError(t, err)
+	assert.Same(t, inflightOf(repo), inflightOf(manager))
+	assert.NotSame(t, defaultInflight, inflightOf(manager))
+
+	// the errors of resolving
+	manager = NewManagerFromConfig(&Config{Default: "unknown"})
+	_, err = manager.Put(ctx, "test", 1, time.Second)
+	assert.ErrorIs(t, err, ErrStoreNotConfigured)
+}
+
+func TestManager_Concurrent(t *testing.T) {
+	var (
+		building = make(chan struct{})
+		release  = make(chan struct{})
+		built    atomic.Int64
+		closed   atomic.Int64
+	)
+
+	manager := NewManagerFromConfig(&Config{
+		Stores: map[string]StoreConfig{
+			"slow": {Driver: "slow"},
+			"null": {Driver: "null"},
+		},
+	}, WithDriver("slow", func(StoreConfig, codec.Codec) (Store, error) {
+		if built.Add(1) == 1 {
+			close(building)
+		}
+		<-release
+		return &countedStore{closed: &closed}, nil
+	}))
+
+	var wg sync.WaitGroup
+	results := make([]Repository, 2)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = manager.Resolve("slow")
+		}()
+	}
+	<-building
+
+	// the slow driver does not block the other lookups
+	r, err := manager.Resolve("null")
+	assert.NoError(t, err)
+	assert.NotNil(t, r)
+
+	close(release)
+	wg.Wait()
+
+	// the same repository, and the duplicated store is closed
+	assert.Same(t, results[0], results[1])
+	assert.Equal(t, built.Load()-1, closed.Load())
+}
+
+type countedStore struct {
+	NullStore
+	closed *atomic.Int64
+}
+
+func (s *countedStore) Close() error {
+	s.closed.Add(1)
+	return nil
+}
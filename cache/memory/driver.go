@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+)
+
+// Driver returns the factory of the memory store for cache.Manager.
+func Driver(opts ...Option) cache.DriverFactory {
+	return func(config cache.StoreConfig, codec codec.Codec) (cache.Store, error) {
+		return New(append([]Option{Prefix(config.Prefix), Codec(codec)}, opts...)...), nil
+	}
+}
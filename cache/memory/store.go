@@ -0,0 +1,355 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+	"github.com/go-kratos-ecosystem/components/v2/codec/json"
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+	memorylocker "github.com/go-kratos-ecosystem/components/v2/locker/memory"
+)
+
+type Store struct {
+	items map[string]*list.Element
+	lru   *list.List
+	locks *memorylocker.Store
+	mu    sync.Mutex
+
+	stop chan struct{}
+	once sync.Once
+
+	opts *options
+}
+
+type item struct {
+	key        string
+	value      []byte
+	expiration time.Time // zero means forever
+}
+
+func (i *item) expired(now time.Time) bool {
+	return !i.expiration.IsZero() && !now.Before(i.expiration)
+}
+
+type options struct {
+	prefix   string
+	codec    codec.Codec
+	size     int
+	interval time.Duration
+}
+
+type Option func(*options)
+
+func Prefix(prefix string) Option {
+	return func(o *options) {
+		if prefix != "" {
+			o.prefix = prefix + ":"
+		}
+	}
+}
+
+func Codec(codec codec.Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// Size sets the maximum number of items in the store.
+// When the size is reached, the least recently used item will be evicted.
+// If the size is less than or equal to 0, the store is unbounded.
+func Size(size int) Option {
+	return func(o *options) {
+		o.size = size
+	}
+}
+
+// CleanupInterval sets the interval of the janitor which removes the expired items.
+// If the interval is less than or equal to 0, the janitor will not be started,
+// and the expired items are only removed when they are accessed.
+func CleanupInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.interval = interval
+	}
+}
+
+var (
+	_ cache.Store   = (*Store)(nil)
+	_ cache.Addable = (*Store)(nil)
+)
+
+func New(opts ...Option) *Store {
+	opt := &options{
+		codec:    json.Codec,
+		interval: time.Minute,
+	}
+
+	for _, o := range opts {
+		o(opt)
+	}
+
+	s := &Store{
+		items: make(map[string]*list.Element),
+		lru:   list.New(),
+		locks: memorylocker.New(),
+		stop:  make(chan struct{}),
+		opts:  opt,
+	}
+
+	if opt.interval > 0 {
+		go s.janitor(opt.interval)
+	}
+
+	return s
+}
+
+func (s *Store) Has(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.lookup(s.opts.prefix+key, time.Now())
+	return ok, nil
+}
+
+func (s *Store) Get(_ context.Context, key string, dest any) error {
+	s.mu.Lock()
+	it, ok := s.lookup(s.opts.prefix+key, time.Now())
+	if !ok {
+		s.mu.Unlock()
+		return cache.ErrNotFound
+	}
+	value := it.value
+	s.mu.Unlock()
+
+	return s.opts.codec.Unmarshal(value, dest)
+}
+
+func (s *Store) TTL(_ context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	it, ok := s.lookup(s.opts.prefix+key, now)
+	if !ok {
+		return 0, cache.ErrNotFound
+	}
+	if it.expiration.IsZero() {
+		return 0, nil
+	}
+
+	return it.expiration.Sub(now), nil
+}
+
+func (s *Store) Put(_ context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	valued, err := s.opts.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(s.opts.prefix+key, valued, s.expiration(ttl))
+
+	return true, nil
+}
+
+func (s *Store) Increment(ctx context.Context, key string, value int) (int, error) {
+	return s.incrBy(ctx, key, value)
+}
+
+func (s *Store) Decrement(ctx context.Context, key string, value int) (int, error) {
+	return s.incrBy(ctx, key, -value)
+}
+
+func (s *Store) Forever(_ context.Context, key string, value any) (bool, error) {
+	valued, err := s.opts.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(s.opts.prefix+key, valued, time.Time{})
+
+	return true, nil
+}
+
+func (s *Store) Forget(_ context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.items[s.opts.prefix+key]
+	if !ok {
+		return false, nil
+	}
+	s.remove(e)
+
+	return !e.Value.(*item).expired(time.Now()), nil
+}
+
+func (s *Store) Flush(context.Context) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = make(map[string]*list.Element)
+	s.lru.Init()
+
+	return true, nil
+}
+
+func (s *Store) GetPrefix() string {
+	return s.opts.prefix
+}
+
+func (s *Store) Add(_ context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	valued, err := s.opts.codec.Marshal(value)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lookup(s.opts.prefix+key, time.Now()); ok {
+		return false, nil
+	}
+
+	s.set(s.opts.prefix+key, valued, s.expiration(ttl))
+
+	return true, nil
+}
+
+func (s *Store) Lock(key string, ttl time.Duration) locker.Locker {
+	return memorylocker.NewLocker(s.locks, memorylocker.WithName(s.opts.prefix+key), memorylocker.WithTTL(ttl))
+}
+
+// Len returns the number of items in the store, including the expired items
+// which have not been removed yet.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lru.Len()
+}
+
+// Close stops the janitor of the store.
+func (s *Store) Close() error {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+	return nil
+}
+
+func (s *Store) incrBy(_ context.Context, key string, value int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		current    int
+		expiration time.Time
+	)
+	if it, ok := s.lookup(s.opts.prefix+key, time.Now()); ok {
+		if err := s.opts.codec.Unmarshal(it.value, &current); err != nil {
+			return 0, err
+		}
+		expiration = it.expiration
+	}
+
+	current += value
+
+	valued, err := s.opts.codec.Marshal(current)
+	if err != nil {
+		return 0, err
+	}
+
+	s.set(s.opts.prefix+key, valued, expiration)
+
+	return current, nil
+}
+
+// lookup returns the unexpired item of the key, and marks it as recently used.
+// The expired item will be removed. The caller must hold the lock.
+func (s *Store) lookup(key string, now time.Time) (*item, bool) {
+	e, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	it := e.Value.(*item)
+	if it.expired(now) {
+		s.remove(e)
+		return nil, false
+	}
+
+	s.lru.MoveToFront(e)
+
+	return it, true
+}
+
+// set stores the item and evicts the least recently used items if the size is exceeded.
+// The caller must hold the lock.
+func (s *Store) set(key string, value []byte, expiration time.Time) {
+	if e, ok := s.items[key]; ok {
+		it := e.Value.(*item)
+		it.value = value
+		it.expiration = expiration
+		s.lru.MoveToFront(e)
+		return
+	}
+
+	s.items[key] = s.lru.PushFront(&item{
+		key:        key,
+		value:      value,
+		expiration: expiration,
+	})
+
+	for s.opts.size > 0 && s.lru.Len() > s.opts.size {
+		s.remove(s.lru.Back())
+	}
+}
+
+// remove removes the element from the store. The caller must hold the lock.
+func (s *Store) remove(e *list.Element) {
+	s.lru.Remove(e)
+	delete(s.items, e.Value.(*item).key)
+}
+
+func (s *Store) expiration(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+func (s *Store) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.deleteExpired()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *Store) deleteExpired() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for e := s.lru.Back(); e != nil; {
+		prev := e.Prev()
+		if e.Value.(*item).expired(now) {
+			s.remove(e)
+		}
+		e = prev
+	}
+}
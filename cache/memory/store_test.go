@@ -0,0 +1,268 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+var ctx = context.Background()
+
+func createStore(t *testing.T, opts ...Option) *Store {
+	store := New(opts...)
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+	return store
+}
+
+func TestMemory_Base(t *testing.T) {
+	store := createStore(t, Prefix("cache:memory"))
+	assert.Equal(t, "cache:memory:", store.GetPrefix())
+
+	ok1, err := store.Put(ctx, "test", "test", time.Millisecond*100)
+	assert.Nil(t, err)
+	assert.True(t, ok1)
+
+	var v string
+	assert.Nil(t, store.Get(ctx, "test", &v))
+	assert.Equal(t, "test", v)
+
+	ok2, err := store.Has(ctx, "test")
+	assert.Nil(t, err)
+	assert.True(t, ok2)
+
+	time.Sleep(time.Millisecond * 150)
+
+	ok3, err := store.Has(ctx, "test")
+	assert.Nil(t, err)
+	assert.False(t, ok3)
+
+	err = store.Get(ctx, "test", &v)
+	assert.True(t, errors.Is(err, cache.ErrNotFound))
+}
+
+func TestMemory_IncrAndDecr(t *testing.T) {
+	store := createStore(t)
+
+	v1, err := store.Increment(ctx, "test:inc", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v1)
+
+	v2, err := store.Increment(ctx, "test:inc", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 11, v2)
+
+	v3, err := store.Decrement(ctx, "test:inc", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 10, v3)
+
+	// keep the ttl
+	ok1, err := store.Put(ctx, "test:inc:ttl", 1, time.Millisecond*100)
+	assert.Nil(t, err)
+	assert.True(t, ok1)
+
+	v4, err := store.Increment(ctx, "test:inc:ttl", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, v4)
+
+	time.Sleep(time.Millisecond * 150)
+
+	ok2, err := store.Has(ctx, "test:inc:ttl")
+	assert.Nil(t, err)
+	assert.False(t, ok2)
+
+	// put another type
+	ok3, err := store.Put(ctx, "test:inc:type", "test", time.Second)
+	assert.Nil(t, err)
+	assert.True(t, ok3)
+
+	v5, err := store.Increment(ctx, "test:inc:type", 1)
+	assert.Error(t, err)
+	assert.Zero(t, v5)
+
+	v6, err := store.Decrement(ctx, "test:inc:type", 1)
+	assert.Error(t, err)
+	assert.Zero(t, v6)
+}
+
+func TestMemory_ForeverAndForget(t *testing.T) {
+	store := createStore(t)
+
+	ok1, err := store.Put(ctx, "test:forever", "test", time.Millisecond*50)
+	assert.Nil(t, err)
+	assert.True(t, ok1)
+
+	ok2, err := store.Forever(ctx, "test:forever", "test")
+	assert.Nil(t, err)
+	assert.True(t, ok2)
+
+	time.Sleep(time.Millisecond * 100)
+
+	ok3, err := store.Has(ctx, "test:forever")
+	assert.Nil(t, err)
+	assert.True(t, ok3)
+
+	ok4, err := store.Forget(ctx, "test:forever")
+	assert.Nil(t, err)
+	assert.True(t, ok4)
+
+	ok5, err := store.Forget(ctx, "test:forever")
+	assert.Nil(t, err)
+	assert.False(t, ok5)
+}
+
+func TestMemory_Flush(t *testing.T) {
+	store := createStore(t)
+
+	ok1, err := store.Put(ctx, "test:flush", "test", time.Second)
+	assert.Nil(t, err)
+	assert.True(t, ok1)
+
+	ok2, err := store.Flush(ctx)
+	assert.Nil(t, err)
+	assert.True(t, ok2)
+
+	ok3, err := store.Has(ctx, "test:flush")
+	assert.NoError(t, err)
+	assert.False(t, ok3)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemory_Add(t *testing.T) {
+	store := createStore(t)
+
+	ok1, err := store.Add(ctx, "test:add", "test", time.Millisecond*100)
+	assert.Nil(t, err)
+	assert.True(t, ok1)
+
+	ok2, err := store.Add(ctx, "test:add", "test", time.Millisecond*100)
+	assert.Nil(t, err)
+	assert.False(t, ok2)
+
+	time.Sleep(time.Millisecond * 150)
+
+	ok3, err := store.Add(ctx, "test:add", "test", time.Millisecond*100)
+	assert.Nil(t, err)
+	assert.True(t, ok3)
+}
+
+func TestMemory_Size(t *testing.T) {
+	store := createStore(t, Size(2))
+
+	_, _ = store.Put(ctx, "a", 1, 0)
+	_, _ = store.Put(ctx, "b", 2, 0)
+
+	// touch a, so b is the least recently used
+	var v int
+	assert.NoError(t, store.Get(ctx, "a", &v))
+
+	_, _ = store.Put(ctx, "c", 3, 0)
+	assert.Equal(t, 2, store.Len())
+
+	ok1, _ := store.Has(ctx, "a")
+	assert.True(t, ok1)
+	ok2, _ := store.Has(ctx, "b")
+	assert.False(t, ok2)
+	ok3, _ := store.Has(ctx, "c")
+	assert.True(t, ok3)
+}
+
+func TestMemory_CleanupInterval(t *testing.T) {
+	store := createStore(t, CleanupInterval(time.Millisecond*10))
+
+	_, _ = store.Put(ctx, "test:cleanup", "test", time.Millisecond*20)
+	assert.Equal(t, 1, store.Len())
+
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestMemory_Lock(t *testing.T) {
+	store := createStore(t)
+	var wg sync.WaitGroup
+	var s int64
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.Lock("test", 5*time.Second).Try(ctx, func() {
+				time.Sleep(time.Millisecond * 100)
+			})
+			if err != nil {
+				assert.True(t, errors.Is(err, locker.ErrLocked))
+			} else {
+				atomic.AddInt64(&s, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.True(t, s > 0)
+	assert.True(t, s < 10)
+}
+
+func TestMemory_LockOwner(t *testing.T) {
+	store := createStore(t, Size(1))
+	l := store.Lock("test:owner", time.Millisecond*100)
+
+	owner1, err := l.Get(ctx)
+	assert.NoError(t, err)
+
+	// the lock is not evicted by the cached items
+	_, _ = store.Put(ctx, "a", 1, 0)
+	_, _ = store.Put(ctx, "b", 2, 0)
+
+	owner, err := l.LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, owner1.Name(), owner.Name())
+
+	_, err = l.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	assert.ErrorIs(t, l.Release(ctx, locker.NewOwner(l)), locker.ErrNotLocked)
+	assert.NoError(t, owner1.Release(ctx))
+
+	// expired
+	_, err = l.Get(ctx)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 150)
+	_, err = l.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+
+	// until
+	owner2, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, l.Until(ctx, time.Millisecond*20, func() {}), locker.ErrTimeout)
+	assert.NoError(t, l.Until(ctx, time.Millisecond*200, func() {}))
+	assert.ErrorIs(t, owner2.Release(ctx), locker.ErrNotLocked)
+
+	// force release
+	_, err = l.Get(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, l.ForceRelease(ctx))
+	_, err = l.Get(ctx)
+	assert.NoError(t, err)
+}
+
+func TestMemory_Driver(t *testing.T) {
+	manager := cache.NewManagerFromConfig(&cache.Config{
+		Default: "memory",
+		Stores: map[string]cache.StoreConfig{
+			"memory": {Driver: "memory", Prefix: "cache:memory"},
+		},
+	}, cache.WithDriver("memory", Driver(CleanupInterval(0))))
+	defer manager.Close()
+
+	repo, err := manager.Resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, "cache:memory:", repo.GetPrefix())
+}
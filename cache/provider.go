@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"context"
+)
+
+type Provider struct {
+	*Manager
+}
+
+func NewProvider(manager *Manager) *Provider {
+	return &Provider{
+		Manager: manager,
+	}
+}
+
+func (p *Provider) Bootstrap(ctx context.Context) (context.Context, error) {
+	return NewContext(ctx, p.Manager), nil
+}
+
+func (p *Provider) Terminate(ctx context.Context) (context.Context, error) {
+	return ctx, p.Manager.Close()
+}
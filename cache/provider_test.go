@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvider(t *testing.T) {
+	m := NewManager(nil)
+	p := NewProvider(m)
+
+	ctx, err := p.Bootstrap(context.Background())
+	assert.NoError(t, err)
+
+	m1, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, m, m1)
+
+	ctx, err = p.Terminate(ctx)
+	assert.NoError(t, err)
+
+	m2, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, m, m2)
+}
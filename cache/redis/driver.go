@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+)
+
+// Driver returns the factory of the redis store for cache.Manager.
+// The connection of the config is resolved by the given function.
+//
+// Example:
+//
+//	redisStore.Driver(func(name string) (redis.UniversalClient, error) {
+//		return redisManager.Conn(name), nil
+//	})
+func Driver(connection func(name string) (redis.UniversalClient, error), opts ...Option) cache.DriverFactory {
+	return func(config cache.StoreConfig, codec codec.Codec) (cache.Store, error) {
+		client, err := connection(config.Connection)
+		if err != nil {
+			return nil, err
+		}
+
+		return New(client, append([]Option{Prefix(config.Prefix), Codec(codec)}, opts...)...), nil
+	}
+}
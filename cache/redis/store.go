@@ -3,6 +3,8 @@ package redis
 import (
 	"context"
 	"errors"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -14,6 +16,9 @@ import (
 	redislocker "github.com/go-kratos-ecosystem/components/v2/locker/redis"
 )
 
+// flushBatchSize is the number of keys scanned and removed in a batch.
+const flushBatchSize = 1000
+
 type Store struct {
 	redis redis.UniversalClient
 
@@ -42,8 +47,10 @@ func Codec(codec codec.Codec) Option {
 }
 
 var (
-	_ cache.Store   = (*Store)(nil)
-	_ cache.Addable = (*Store)(nil)
+	_ cache.Store     = (*Store)(nil)
+	_ cache.Addable   = (*Store)(nil)
+	_ cache.Bulkable  = (*Store)(nil)
+	_ cache.Expirable = (*Store)(nil)
 )
 
 func New(redis redis.UniversalClient, opts ...Option) *Store {
@@ -82,6 +89,23 @@ func (s *Store) Get(ctx context.Context, key string, dest any) error {
 	return s.opts.codec.Unmarshal([]byte(r.Val()), dest)
 }
 
+func (s *Store) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.redis.PTTL(ctx, s.opts.prefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// -2 means the key does not exist, and -1 means the key never expires
+	switch {
+	case ttl == -2*time.Millisecond:
+		return 0, cache.ErrNotFound
+	case ttl < 0:
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
 func (s *Store) Put(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
 	valued, err := s.opts.codec.Marshal(value)
 	if err != nil {
@@ -137,13 +161,23 @@ func (s *Store) Forget(ctx context.Context, key string) (bool, error) {
 	return r.Val() > 0, nil
 }
 
+// Flush removes all the keys with the prefix of the store.
+// If the prefix is empty, all the keys of the current database will be removed.
 func (s *Store) Flush(ctx context.Context) (bool, error) {
-	r := s.redis.FlushAll(ctx)
-	if r.Err() != nil {
-		return false, r.Err()
+	if c, ok := s.redis.(*redis.ClusterClient); ok {
+		if err := c.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+			return s.flush(ctx, client)
+		}); err != nil {
+			return false, err
+		}
+		return true, nil
 	}
 
-	return r.Val() == "OK", nil
+	if err := s.flush(ctx, s.redis); err != nil {
+		return false, err
+	}
+
+	return true, nil
 }
 
 func (s *Store) GetPrefix() string {
@@ -164,9 +198,142 @@ func (s *Store) Add(ctx context.Context, key string, value any, ttl time.Duratio
 	return r.Val(), nil
 }
 
+func (s *Store) Many(ctx context.Context, keys []string, dest any) error {
+	m := reflect.ValueOf(dest)
+	if m.Kind() != reflect.Map || m.IsNil() || m.Type().Key().Kind() != reflect.String {
+		return cache.ErrInvalidDest
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// the pipelined GETs instead of MGET, since the keys may be in the different slots of the cluster
+	cmds := make([]*redis.StringCmd, len(keys))
+	if _, err := s.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, s.opts.prefix+key)
+		}
+		return nil
+	}); err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	for i, cmd := range cmds {
+		val, err := cmd.Bytes()
+		if errors.Is(err, redis.Nil) { // the key does not exist
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		value := reflect.New(m.Type().Elem())
+		if err := s.opts.codec.Unmarshal(val, value.Interface()); err != nil {
+			return err
+		}
+		m.SetMapIndex(reflect.ValueOf(keys[i]).Convert(m.Type().Key()), value.Elem())
+	}
+
+	return nil
+}
+
+func (s *Store) PutMany(ctx context.Context, values map[string]any, ttl time.Duration) (bool, error) {
+	valued := make(map[string][]byte, len(values))
+	for key, value := range values {
+		v, err := s.opts.codec.Marshal(value)
+		if err != nil {
+			return false, err
+		}
+		valued[key] = v
+	}
+
+	if _, err := s.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, value := range valued {
+			pipe.Set(ctx, s.opts.prefix+key, value, ttl)
+		}
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *Store) ForgetMany(ctx context.Context, keys ...string) (bool, error) {
+	cmds, err := s.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, s.opts.prefix+key)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var forgot bool
+	for _, cmd := range cmds {
+		forgot = forgot || cmd.(*redis.IntCmd).Val() > 0
+	}
+
+	return forgot, nil
+}
+
 func (s *Store) Lock(key string, ttl time.Duration) locker.Locker {
 	return redislocker.NewLocker(s.redis,
 		redislocker.WithName(s.opts.prefix+key),
 		redislocker.WithTTL(ttl),
 	)
 }
+
+// flush scans the keys with the prefix, and removes them in batches.
+func (s *Store) flush(ctx context.Context, client redis.UniversalClient) error {
+	iter := client.Scan(ctx, 0, escapePattern(s.opts.prefix)+"*", flushBatchSize).Iterator()
+
+	keys := make([]string, 0, flushBatchSize)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) < flushBatchSize {
+			continue
+		}
+
+		if err := s.unlink(ctx, keys); err != nil {
+			return err
+		}
+		keys = keys[:0]
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	return s.unlink(ctx, keys)
+}
+
+// unlink removes the keys in batches. Each key is unlinked by its own command,
+// so the keys in different slots of the cluster can be removed together.
+func (s *Store) unlink(ctx context.Context, keys []string) error {
+	for len(keys) > 0 {
+		n := min(len(keys), flushBatchSize)
+		if _, err := s.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range keys[:n] {
+				pipe.Unlink(ctx, key)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+
+	return nil
+}
+
+// escapePattern escapes the glob-style special characters of the pattern.
+func escapePattern(pattern string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`*`, `\*`,
+		`?`, `\?`,
+		`[`, `\[`,
+		`]`, `\]`,
+	).Replace(pattern)
+}
@@ -3,6 +3,7 @@ package redis
 import (
 	"context"
 	"errors"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/go-kratos-ecosystem/components/v2/cache"
 	"github.com/go-kratos-ecosystem/components/v2/codec/json"
+	"github.com/go-kratos-ecosystem/components/v2/event"
 	"github.com/go-kratos-ecosystem/components/v2/locker"
 )
 
@@ -113,6 +115,61 @@ func TestRedis_Flush(t *testing.T) {
 	assert.False(t, ok3)
 }
 
+func TestRedis_FlushPrefix(t *testing.T) {
+	client := createRedis(t)
+	store := New(client, Prefix("cache:redis:flush"))
+	other := New(client, Prefix("cache:redis:other"))
+
+	for i := 0; i < 2500; i++ {
+		_, err := store.Put(ctx, "test:flush:"+strconv.Itoa(i), i, time.Minute)
+		assert.NoError(t, err)
+	}
+	_, err := other.Put(ctx, "test:flush", "test", time.Minute)
+	assert.NoError(t, err)
+
+	ok1, err := store.Flush(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok1)
+
+	keys, err := client.Keys(ctx, "cache:redis:flush:*").Result()
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	ok2, err := other.Has(ctx, "test:flush")
+	assert.NoError(t, err)
+	assert.True(t, ok2)
+}
+
+func TestRedis_Many(t *testing.T) {
+	store := New(createRedis(t), Prefix("cache:redis"))
+
+	ok1, err := store.PutMany(ctx, map[string]any{
+		"test:many:1": 1,
+		"test:many:2": 2,
+	}, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok1)
+
+	dest := make(map[string]int)
+	assert.NoError(t, store.Many(ctx, []string{"test:many:1", "test:many:2", "test:many:3"}, dest))
+	assert.Equal(t, map[string]int{"test:many:1": 1, "test:many:2": 2}, dest)
+
+	assert.ErrorIs(t, store.Many(ctx, []string{"test:many:1"}, nil), cache.ErrInvalidDest)
+	assert.ErrorIs(t, store.Many(ctx, []string{"test:many:1"}, map[int]int{}), cache.ErrInvalidDest)
+
+	ok2, err := store.ForgetMany(ctx, "test:many:1", "test:many:3")
+	assert.NoError(t, err)
+	assert.True(t, ok2)
+
+	ok3, err := store.ForgetMany(ctx, "test:many:1", "test:many:3")
+	assert.NoError(t, err)
+	assert.False(t, ok3)
+
+	ok4, err := store.Has(ctx, "test:many:2")
+	assert.NoError(t, err)
+	assert.True(t, ok4)
+}
+
 func TestRedis_Add(t *testing.T) {
 	store := New(createRedis(t), Prefix("cache:redis"))
 
@@ -167,3 +224,152 @@ func TestRedis_ErrNotFound(t *testing.T) {
 	assert.True(t, errors.Is(err, cache.ErrNotFound))
 	assert.Empty(t, v)
 }
+
+func TestRedis_Tags(t *testing.T) {
+	store := New(createRedis(t), Prefix("cache:redis"))
+	users := store.Tags("users")
+	tenants := store.Tags("tenants", "users")
+
+	ok1, err := users.Put(ctx, "test:tags:1", "user", time.Second*10)
+	assert.Nil(t, err)
+	assert.True(t, ok1)
+
+	ok2, err := tenants.Forever(ctx, "test:tags:1", "tenant")
+	assert.Nil(t, err)
+	assert.True(t, ok2)
+
+	v1, err := tenants.Increment(ctx, "test:tags:inc", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, v1)
+
+	ok3, err := store.Put(ctx, "test:tags:1", "plain", time.Second*10)
+	assert.Nil(t, err)
+	assert.True(t, ok3)
+
+	// the keys are only visible to the same tags
+	var v string
+	assert.Nil(t, users.Get(ctx, "test:tags:1", &v))
+	assert.Equal(t, "user", v)
+	assert.Nil(t, store.Tags("users", "tenants").Get(ctx, "test:tags:1", &v))
+	assert.Equal(t, "tenant", v)
+	assert.Nil(t, store.Get(ctx, "test:tags:1", &v))
+	assert.Equal(t, "plain", v)
+
+	// flush the keys recorded under the users tag
+	ok4, err := store.Tags("users").Flush(ctx)
+	assert.Nil(t, err)
+	assert.True(t, ok4)
+
+	ok5, err := users.Has(ctx, "test:tags:1")
+	assert.Nil(t, err)
+	assert.False(t, ok5)
+
+	ok6, err := tenants.Has(ctx, "test:tags:1")
+	assert.Nil(t, err)
+	assert.False(t, ok6)
+
+	ok7, err := store.Has(ctx, "test:tags:1")
+	assert.Nil(t, err)
+	assert.True(t, ok7)
+
+	// repository
+	repo := cache.NewRepository(store)
+	ok8, err := repo.Tags("users").Set(ctx, "test:tags:2", "user", time.Second*10)
+	assert.Nil(t, err)
+	assert.True(t, ok8)
+
+	ok9, err := repo.Tags("users").Has(ctx, "test:tags:2")
+	assert.Nil(t, err)
+	assert.True(t, ok9)
+}
+
+func TestRedis_TagsRemember(t *testing.T) {
+	store := New(createRedis(t), Prefix("tags:remember"))
+	repo := cache.NewRepository(store)
+
+	var (
+		calls   atomic.Int64
+		wg      sync.WaitGroup
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	load := func(v string) func() (string, error) {
+		return func() (string, error) {
+			if calls.Add(1) == 1 {
+				close(started)
+			}
+			<-release
+			return v, nil
+		}
+	}
+
+	// the loads of the same tags are shared, in any order
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := cache.Remember(ctx, repo.Tags("users", "posts"), "key", time.Second, load("shared"))
+		assert.NoError(t, err)
+		assert.Equal(t, "shared", v)
+	}()
+	<-started
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := cache.Remember(ctx, repo.Tags("posts", "users"), "key", time.Second, load("other"))
+		assert.NoError(t, err)
+		assert.Equal(t, "shared", v)
+	}()
+
+	// the other tags are not shared
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := cache.Remember(ctx, repo.Tags("users"), "key", time.Second, load("users"))
+		assert.NoError(t, err)
+		assert.Equal(t, "users", v)
+	}()
+
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 2
+	}, time.Second, time.Millisecond*10)
+	time.Sleep(time.Millisecond * 20)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int64(2), calls.Load())
+}
+
+type hitListener struct {
+	events []event.Event
+}
+
+func (l *hitListener) Listen() []event.Event {
+	return []event.Event{&cache.CacheHit{}, &cache.CacheMissed{}}
+}
+
+func (l *hitListener) Handle(e event.Event) {
+	l.events = append(l.events, e)
+}
+
+func TestRedis_ManyEvents(t *testing.T) {
+	listener := &hitListener{}
+	dispatcher := event.NewDispatcher()
+	dispatcher.AddListener(listener)
+
+	store := New(createRedis(t), Prefix("many:events"))
+	repo := cache.NewRepository(store, cache.WithName("redis"), cache.WithDispatcher(dispatcher))
+
+	_, err := repo.Put(ctx, "found", 1, time.Second)
+	assert.NoError(t, err)
+
+	// the keys already in dest are kept, but they are not hits
+	values := map[string]int{"none": 2}
+	assert.NoError(t, repo.Many(ctx, []string{"found", "none"}, values))
+	assert.Equal(t, map[string]int{"found": 1, "none": 2}, values)
+
+	assert.Equal(t, []event.Event{
+		&cache.CacheHit{Store: "redis", Key: "found"},
+		&cache.CacheMissed{Store: "redis", Key: "none"},
+	}, listener.events)
+}
@@ -0,0 +1,148 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// TaggedStore is a store whose keys are recorded under the tags.
+//
+// The keys are namespaced by the tags, and each tag keeps a sorted set of
+// the recorded keys scored by their expiration time.
+type TaggedStore struct {
+	store     *Store
+	tags      []string
+	namespace string
+}
+
+var (
+	_ cache.Store    = (*TaggedStore)(nil)
+	_ cache.Addable  = (*TaggedStore)(nil)
+	_ cache.Taggable = (*Store)(nil)
+)
+
+func (s *Store) Tags(names ...string) cache.Store {
+	tags := make([]string, len(names))
+	copy(tags, names)
+	sort.Strings(tags)
+
+	sum := sha1.Sum([]byte(strings.Join(tags, "|"))) //nolint:gosec
+
+	return &TaggedStore{
+		store:     s,
+		tags:      tags,
+		namespace: hex.EncodeToString(sum[:]) + ":",
+	}
+}
+
+func (t *TaggedStore) Has(ctx context.Context, key string) (bool, error) {
+	return t.store.Has(ctx, t.namespace+key)
+}
+
+func (t *TaggedStore) Get(ctx context.Context, key string, dest any) error {
+	return t.store.Get(ctx, t.namespace+key, dest)
+}
+
+func (t *TaggedStore) Put(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	if err := t.record(ctx, key, ttl); err != nil {
+		return false, err
+	}
+
+	return t.store.Put(ctx, t.namespace+key, value, ttl)
+}
+
+func (t *TaggedStore) Increment(ctx context.Context, key string, value int) (int, error) {
+	if err := t.record(ctx, key, 0); err != nil {
+		return 0, err
+	}
+
+	return t.store.Increment(ctx, t.namespace+key, value)
+}
+
+func (t *TaggedStore) Decrement(ctx context.Context, key string, value int) (int, error) {
+	if err := t.record(ctx, key, 0); err != nil {
+		return 0, err
+	}
+
+	return t.store.Decrement(ctx, t.namespace+key, value)
+}
+
+func (t *TaggedStore) Forever(ctx context.Context, key string, value any) (bool, error) {
+	if err := t.record(ctx, key, 0); err != nil {
+		return false, err
+	}
+
+	return t.store.Forever(ctx, t.namespace+key, value)
+}
+
+func (t *TaggedStore) Forget(ctx context.Context, key string) (bool, error) {
+	return t.store.Forget(ctx, t.namespace+key)
+}
+
+// Flush removes all the keys recorded under the tags, including the keys
+// recorded by the stores with other tags, and then removes the tags.
+func (t *TaggedStore) Flush(ctx context.Context) (bool, error) {
+	for _, tag := range t.tags {
+		keys, err := t.store.redis.ZRange(ctx, t.tagKey(tag), 0, -1).Result()
+		if err != nil {
+			return false, err
+		}
+
+		if err := t.store.unlink(ctx, append(keys, t.tagKey(tag))); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+func (t *TaggedStore) GetPrefix() string {
+	return t.store.GetPrefix()
+}
+
+func (t *TaggedStore) Add(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	if err := t.record(ctx, key, ttl); err != nil {
+		return false, err
+	}
+
+	return t.store.Add(ctx, t.namespace+key, value, ttl)
+}
+
+func (t *TaggedStore) Lock(key string, ttl time.Duration) locker.Locker {
+	return t.store.Lock(t.namespace+key, ttl)
+}
+
+// record adds the key to the sorted set of each tag, and prunes the expired keys.
+func (t *TaggedStore) record(ctx context.Context, key string, ttl time.Duration) error {
+	score := math.Inf(1)
+	if ttl > 0 {
+		score = float64(time.Now().Add(ttl).Unix())
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	member := t.store.opts.prefix + t.namespace + key
+
+	_, err := t.store.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, tag := range t.tags {
+			pipe.ZAdd(ctx, t.tagKey(tag), redis.Z{Score: score, Member: member})
+			pipe.ZRemRangeByScore(ctx, t.tagKey(tag), "0", "("+now)
+		}
+		return nil
+	})
+
+	return err
+}
+
+func (t *TaggedStore) tagKey(tag string) string {
+	return t.store.opts.prefix + "tag:" + tag + ":entries"
+}
@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// inflight deduplicates the concurrent loads and refreshes of the keys of a repository within the process.
+type inflight struct {
+	namespace  string // the tags of the repository
+	loads      singleflight.Group
+	refreshing sync.Map
+}
+
+// id returns the id of the key loaded as T.
+func (f *inflight) id(repo Repository, key string, typ reflect.Type) string {
+	return f.namespace + repo.GetPrefix() + key + "@" + typ.String()
+}
+
+// defaultInflight is used by the repositories not created by NewRepository.
+var defaultInflight = &inflight{}
+
+func inflightOf(repo Repository) *inflight {
+	if r, ok := repo.(interface{ inflight() *inflight }); ok {
+		return r.inflight()
+	}
+	return defaultInflight
+}
+
+type rememberOptions struct {
+	locked      bool
+	lockTTL     time.Duration
+	lockTimeout time.Duration
+
+	// for Flexible
+	beta                float64
+	refreshErrorHandler func(key string, err error)
+}
+
+type RememberOption func(*rememberOptions)
+
+// WithRememberLock deduplicates the loads across the processes with the lock of the store.
+// The ttl is the ttl of the lock, and the timeout is the maximum time to wait for the lock.
+func WithRememberLock(ttl, timeout time.Duration) RememberOption {
+	return func(o *rememberOptions) {
+		o.locked = true
+		o.lockTTL = ttl
+		o.lockTimeout = timeout
+	}
+}
+
+// Remember gets the value of the key from the cache.
+// If the key does not exist, the value returned by fn will be stored with the ttl.
+// The concurrent calls of the same key within the process only call fn once,
+// the callers waiting for the shared call return the error of ctx when it is done.
+//
+// Example:
+//
+//	user, err := cache.Remember(ctx, repo, "user:1", time.Minute, func() (*User, error) {
+//		return findUser(ctx, 1)
+//	})
+func Remember[T any](
+	ctx context.Context, repo Repository, key string, ttl time.Duration, fn func() (T, error), opts ...RememberOption,
+) (T, error) {
+	return remember(ctx, repo, key, fn, func(ctx context.Context, value T) (bool, error) {
+		return repo.Put(ctx, key, value, ttl)
+	}, opts...)
+}
+
+// RememberForever is like Remember, but the value will be stored forever.
+func RememberForever[T any](
+	ctx context.Context, repo Repository, key string, fn func() (T, error), opts ...RememberOption,
+) (T, error) {
+	return remember(ctx, repo, key, fn, func(ctx context.Context, value T) (bool, error) {
+		return repo.Forever(ctx, key, value)
+	}, opts...)
+}
+
+// Pull gets the value of the key from the cache, and then deletes it.
+// If the key does not exist, the return error will be ErrNotFound.
+func Pull[T any](ctx context.Context, repo Repository, key string) (T, error) {
+	var value T
+	if err := repo.Get(ctx, key, &value); err != nil {
+		return value, err
+	}
+
+	if _, err := repo.Forget(ctx, key); err != nil {
+		return value, err
+	}
+
+	return value, nil
+}
+
+func remember[T any](
+	ctx context.Context, repo Repository, key string, fn func() (T, error), put func(context.Context, T) (bool, error),
+	opts ...RememberOption,
+) (T, error) {
+	o := &rememberOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if value, ok, err := lookup[T](ctx, repo, key); err != nil || ok {
+		return value, err
+	}
+
+	load := func(ctx context.Context) (T, error) {
+		// the value may be stored by others while waiting
+		if value, ok, err := lookup[T](ctx, repo, key); err != nil || ok {
+			return value, err
+		}
+
+		value, err := fn()
+		if err != nil {
+			return value, err
+		}
+
+		if _, err := put(ctx, value); err != nil {
+			return value, err
+		}
+
+		return value, nil
+	}
+
+	flight := inflightOf(repo)
+	ch := flight.loads.DoChan(flight.id(repo, key, reflect.TypeFor[T]()), func() (any, error) {
+		// the load is shared by the concurrent callers, so it is not canceled by any of them
+		ctx := context.WithoutCancel(ctx)
+
+		if !o.locked {
+			return load(ctx)
+		}
+
+		var (
+			value T
+			err   error
+		)
+		if e := repo.Lock(key+":remember", o.lockTTL).Until(ctx, o.lockTimeout, func() {
+			value, err = load(ctx)
+		}); e != nil {
+			return value, e
+		}
+
+		return value, err
+	})
+
+	select {
+	case result := <-ch:
+		value, _ := result.Val.(T)
+		return value, result.Err
+	case <-ctx.Done():
+		var value T
+		return value, ctx.Err()
+	}
+}
+
+func lookup[T any](ctx context.Context, repo Repository, key string) (T, bool, error) {
+	var value T
+	if err := repo.Get(ctx, key, &value); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return value, false, nil
+		}
+		return value, false, err
+	}
+
+	return value, true, nil
+}
@@ -0,0 +1,205 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/cache/memory"
+)
+
+func TestRemember(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	defer store.Close()
+	repo := cache.NewRepository(store)
+
+	var calls int64
+	fn := func() (string, error) {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(time.Millisecond * 50)
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := cache.Remember(ctx, repo, "remember", time.Second, fn)
+			assert.NoError(t, err)
+			assert.Equal(t, "value", v)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+
+	// cached
+	v, err := cache.Remember(ctx, repo, "remember", time.Second, fn)
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&calls))
+
+	// error
+	e := errors.New("failed")
+	_, err = cache.Remember(ctx, repo, "remember:error", time.Second, func() (int, error) {
+		return 0, e
+	})
+	assert.ErrorIs(t, err, e)
+	ok, _ := repo.Has(ctx, "remember:error")
+	assert.False(t, ok)
+}
+
+func TestRememberForever(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	defer store.Close()
+	repo := cache.NewRepository(store)
+
+	v, err := cache.RememberForever(ctx, repo, "remember:forever", func() (int, error) {
+		return 1, nil
+	}, cache.WithRememberLock(time.Second, time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	v, err = cache.RememberForever(ctx, repo, "remember:forever", func() (int, error) {
+		return 2, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	// locked by others
+	owner, err := repo.Lock("remember:locked:remember", time.Second).Get(ctx)
+	assert.NoError(t, err)
+	defer owner.Release(ctx) //nolint:errcheck
+
+	_, err = cache.RememberForever(ctx, repo, "remember:locked", func() (int, error) {
+		return 1, nil
+	}, cache.WithRememberLock(time.Second, time.Millisecond*50))
+	assert.Error(t, err)
+}
+
+func TestPull(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+	defer store.Close()
+	repo := cache.NewRepository(store)
+
+	_, err := cache.Pull[string](ctx, repo, "pull")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	_, _ = repo.Set(ctx, "pull", "value", time.Second)
+
+	v, err := cache.Pull[string](ctx, repo, "pull")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+
+	ok, _ := repo.Has(ctx, "pull")
+	assert.False(t, ok)
+}
+
+func TestRemember_Scoped(t *testing.T) {
+	ctx := context.Background()
+	store1, store2 := memory.New(), memory.New()
+	defer store1.Close()
+	defer store2.Close()
+
+	// the repositories with the same prefix on the different stores do not share the loads
+	repo1, repo2 := cache.NewRepository(store1), cache.NewRepository(store2)
+
+	var (
+		wg      sync.WaitGroup
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := cache.Remember(ctx, repo1, "remember:scoped", time.Second, func() (string, error) {
+			close(started)
+			<-release
+			return "v1", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "v1", v)
+	}()
+	<-started
+
+	v, err := cache.Remember(ctx, repo2, "remember:scoped", time.Second, func() (string, error) {
+		return "v2", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", v)
+
+	close(release)
+	wg.Wait()
+}
+
+// ctxStore fails the writes with the canceled context.
+type ctxStore struct {
+	*memory.Store
+}
+
+func (s *ctxStore) Put(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return s.Store.Put(ctx, key, value, ttl)
+}
+
+func TestRemember_Canceled(t *testing.T) {
+	store := &ctxStore{Store: memory.New()}
+	defer store.Close()
+	repo := cache.NewRepository(store)
+
+	var (
+		wg      sync.WaitGroup
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	// the first caller is canceled while loading
+	cctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error, 1)
+	go func() {
+		_, err := cache.Remember(cctx, repo, "remember:canceled", time.Second, func() (string, error) {
+			close(started)
+			<-release
+			return "value", nil
+		})
+		canceled <- err
+	}()
+	<-started
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		v, err := cache.Remember(context.Background(), repo, "remember:canceled", time.Second, func() (string, error) {
+			return "other", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "value", v)
+	}()
+
+	// the canceled caller returns right away, while the load goes on
+	cancel()
+	select {
+	case err := <-canceled:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("the canceled caller is blocked by the load")
+	}
+
+	close(release)
+	wg.Wait()
+
+	ok, err := repo.Has(context.Background(), "remember:canceled")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -2,30 +2,200 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-kratos-ecosystem/components/v2/event"
+)
+
+const instrumentation = "github.com/go-kratos-ecosystem/components/v2/cache"
+
+const (
+	resultHit     = "hit"
+	resultMiss    = "miss"
+	resultSuccess = "success"
+	resultError   = "error"
 )
 
 type Repository interface {
 	Store
 	Addable
+	Bulkable
 
 	Missing(ctx context.Context, key string) (bool, error)
 	Delete(ctx context.Context, key string) (bool, error)
 	Set(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
+
+	// Tags returns a repository whose keys are grouped under the given tags.
+	// If the store does not implement Taggable, all the operations of the returned repository fail with ErrNotTaggable.
+	Tags(names ...string) Repository
 }
 
 type repository struct {
 	Store
+
+	opts     *options
+	flight   *inflight
+	tagged   *sync.Map // the inflight of the tag sets, shared by the tagged repositories
+	tracer   trace.Tracer
+	requests metric.Int64Counter
+	duration metric.Float64Histogram
 }
 
-func NewRepository(store Store) Repository {
-	return &repository{
-		Store: store,
+type options struct {
+	name       string
+	dispatcher *event.Dispatcher
+	mp         metric.MeterProvider
+	tp         trace.TracerProvider
+}
+
+type Option func(*options)
+
+// WithName sets the store name used by the events, metrics and spans.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
 	}
 }
 
+// WithDispatcher dispatches the CacheHit, CacheMissed, KeyWritten and KeyForgotten events.
+func WithDispatcher(dispatcher *event.Dispatcher) Option {
+	return func(o *options) {
+		o.dispatcher = dispatcher
+	}
+}
+
+// WithMeterProvider records the operation counters and latency histograms.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(o *options) {
+		o.mp = mp
+	}
+}
+
+// WithTracerProvider starts a span for each operation.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tp = tp
+	}
+}
+
+func NewRepository(store Store, opts ...Option) Repository {
+	o := &options{
+		name: "default",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	r := &repository{
+		Store:  store,
+		opts:   o,
+		flight: &inflight{},
+		tagged: &sync.Map{},
+	}
+
+	if o.tp != nil {
+		r.tracer = o.tp.Tracer(instrumentation)
+	}
+
+	if o.mp != nil {
+		meter := o.mp.Meter(instrumentation)
+		r.requests, _ = meter.Int64Counter("cache.operations",
+			metric.WithDescription("The number of cache operations."),
+			metric.WithUnit("{operation}"),
+		)
+		r.duration, _ = meter.Float64Histogram("cache.operation.duration",
+			metric.WithDescription("The duration of cache operations."),
+			metric.WithUnit("s"),
+		)
+	}
+
+	return r
+}
+
+func (r *repository) Has(ctx context.Context, key string) (ok bool, err error) {
+	err = r.instrument(ctx, "has", key, func(ctx context.Context) (string, error) {
+		if ok, err = r.Store.Has(ctx, key); err != nil {
+			return resultError, err
+		} else if ok {
+			return resultHit, nil
+		}
+		return resultMiss, nil
+	})
+	return
+}
+
+func (r *repository) Get(ctx context.Context, key string, dest any) error {
+	return r.instrument(ctx, "get", key, func(ctx context.Context) (string, error) {
+		return r.get(ctx, key, dest)
+	})
+}
+
+func (r *repository) Put(ctx context.Context, key string, value any, ttl time.Duration) (ok bool, err error) {
+	err = r.instrument(ctx, "put", key, func(ctx context.Context) (string, error) {
+		ok, err = r.put(ctx, key, value, ttl)
+		return resultSuccess, err
+	})
+	return
+}
+
+func (r *repository) Increment(ctx context.Context, key string, value int) (v int, err error) {
+	err = r.instrument(ctx, "increment", key, func(ctx context.Context) (string, error) {
+		if v, err = r.Store.Increment(ctx, key, value); err == nil {
+			r.dispatch(&KeyWritten{Store: r.opts.name, Key: key})
+		}
+		return resultSuccess, err
+	})
+	return
+}
+
+func (r *repository) Decrement(ctx context.Context, key string, value int) (v int, err error) {
+	err = r.instrument(ctx, "decrement", key, func(ctx context.Context) (string, error) {
+		if v, err = r.Store.Decrement(ctx, key, value); err == nil {
+			r.dispatch(&KeyWritten{Store: r.opts.name, Key: key})
+		}
+		return resultSuccess, err
+	})
+	return
+}
+
+func (r *repository) Forever(ctx context.Context, key string, value any) (ok bool, err error) {
+	err = r.instrument(ctx, "forever", key, func(ctx context.Context) (string, error) {
+		if ok, err = r.Store.Forever(ctx, key, value); err == nil && ok {
+			r.dispatch(&KeyWritten{Store: r.opts.name, Key: key})
+		}
+		return resultSuccess, err
+	})
+	return
+}
+
+func (r *repository) Forget(ctx context.Context, key string) (ok bool, err error) {
+	err = r.instrument(ctx, "forget", key, func(ctx context.Context) (string, error) {
+		ok, err = r.forget(ctx, key)
+		return resultSuccess, err
+	})
+	return
+}
+
+func (r *repository) Flush(ctx context.Context) (ok bool, err error) {
+	err = r.instrument(ctx, "flush", "", func(ctx context.Context) (string, error) {
+		ok, err = r.Store.Flush(ctx)
+		return resultSuccess, err
+	})
+	return
+}
+
 func (r *repository) Missing(ctx context.Context, key string) (bool, error) {
-	had, err := r.Store.Has(ctx, key)
+	had, err := r.Has(ctx, key)
 	if err != nil {
 		return false, err
 	}
@@ -33,24 +203,116 @@ func (r *repository) Missing(ctx context.Context, key string) (bool, error) {
 	return !had, nil
 }
 
-func (r *repository) Add(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
-	// if the store is addable, use it
-	if store, ok := r.Store.(Addable); ok {
-		return store.Add(ctx, key, value, ttl)
-	}
+func (r *repository) Add(ctx context.Context, key string, value any, ttl time.Duration) (ok bool, err error) {
+	err = r.instrument(ctx, "add", key, func(ctx context.Context) (string, error) {
+		// if the store is addable, use it
+		if store, addable := r.Store.(Addable); addable {
+			ok, err = store.Add(ctx, key, value, ttl)
+		} else if had, e := r.Store.Has(ctx, key); e != nil { // otherwise, use the default implementation
+			err = e
+		} else if !had {
+			ok, err = r.Store.Put(ctx, key, value, ttl)
+		}
 
-	// otherwise, use the default implementation
-	if missing, err := r.Missing(ctx, key); err != nil {
-		return false, err
-	} else if missing {
-		status, err := r.Set(ctx, key, value, ttl)
-		if err != nil {
-			return false, err
+		if err == nil && ok {
+			r.dispatch(&KeyWritten{Store: r.opts.name, Key: key, TTL: ttl})
 		}
-		return status, nil
+		return resultSuccess, err
+	})
+	return
+}
+
+func (r *repository) Many(ctx context.Context, keys []string, dest any) error {
+	m := reflect.ValueOf(dest)
+	if m.Kind() != reflect.Map || m.IsNil() || m.Type().Key().Kind() != reflect.String {
+		return ErrInvalidDest
 	}
 
-	return false, nil
+	return r.instrument(ctx, "many", "", func(ctx context.Context) (string, error) {
+		// if the store is bulkable, use it
+		if store, ok := r.Store.(Bulkable); ok {
+			// the values are collected into a new map, so the keys already in dest are not taken as hits
+			found := reflect.MakeMap(m.Type())
+			if err := store.Many(ctx, keys, found.Interface()); err != nil {
+				return resultError, err
+			}
+
+			for _, key := range keys {
+				k := reflect.ValueOf(key).Convert(m.Type().Key())
+				if value := found.MapIndex(k); value.IsValid() {
+					m.SetMapIndex(k, value)
+					r.dispatch(&CacheHit{Store: r.opts.name, Key: key})
+				} else {
+					r.dispatch(&CacheMissed{Store: r.opts.name, Key: key})
+				}
+			}
+			return resultSuccess, nil
+		}
+
+		// otherwise, use the default implementation
+		for _, key := range keys {
+			value := reflect.New(m.Type().Elem())
+			if result, err := r.get(ctx, key, value.Interface()); result == resultMiss {
+				continue
+			} else if err != nil {
+				return resultError, err
+			}
+			m.SetMapIndex(reflect.ValueOf(key).Convert(m.Type().Key()), value.Elem())
+		}
+
+		return resultSuccess, nil
+	})
+}
+
+func (r *repository) PutMany(ctx context.Context, values map[string]any, ttl time.Duration) (ok bool, err error) {
+	err = r.instrument(ctx, "put_many", "", func(ctx context.Context) (string, error) {
+		// if the store is bulkable, use it
+		if store, bulkable := r.Store.(Bulkable); bulkable {
+			if ok, err = store.PutMany(ctx, values, ttl); err == nil && ok {
+				for key := range values {
+					r.dispatch(&KeyWritten{Store: r.opts.name, Key: key, TTL: ttl})
+				}
+			}
+			return resultSuccess, err
+		}
+
+		// otherwise, use the default implementation
+		for key, value := range values {
+			if ok, err = r.put(ctx, key, value, ttl); err != nil || !ok {
+				return resultSuccess, err
+			}
+		}
+
+		ok = true
+		return resultSuccess, nil
+	})
+	return
+}
+
+func (r *repository) ForgetMany(ctx context.Context, keys ...string) (ok bool, err error) {
+	err = r.instrument(ctx, "forget_many", "", func(ctx context.Context) (string, error) {
+		// if the store is bulkable, use it
+		if store, bulkable := r.Store.(Bulkable); bulkable {
+			if ok, err = store.ForgetMany(ctx, keys...); err == nil && ok {
+				for _, key := range keys {
+					r.dispatch(&KeyForgotten{Store: r.opts.name, Key: key})
+				}
+			}
+			return resultSuccess, err
+		}
+
+		// otherwise, use the default implementation
+		for _, key := range keys {
+			forgot, e := r.forget(ctx, key)
+			if e != nil {
+				return resultError, e
+			}
+			ok = ok || forgot
+		}
+
+		return resultSuccess, nil
+	})
+	return
 }
 
 func (r *repository) Delete(ctx context.Context, key string) (bool, error) {
@@ -60,3 +322,120 @@ func (r *repository) Delete(ctx context.Context, key string) (bool, error) {
 func (r *repository) Set(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
 	return r.Put(ctx, key, value, ttl)
 }
+
+func (r *repository) Tags(names ...string) Repository {
+	var tagged Store = &errorStore{err: ErrNotTaggable, prefix: r.GetPrefix()}
+	if store, ok := r.Store.(Taggable); ok {
+		tagged = store.Tags(names...)
+	}
+
+	return &repository{
+		Store:    tagged,
+		opts:     r.opts,
+		flight:   r.tagFlight(names),
+		tagged:   r.tagged,
+		tracer:   r.tracer,
+		requests: r.requests,
+		duration: r.duration,
+	}
+}
+
+// tagFlight returns the inflight of the tag set, so the loads of the repositories with the same tags are shared.
+func (r *repository) tagFlight(names []string) *inflight {
+	tags := slices.Clone(names)
+	slices.Sort(tags)
+	namespace := "tags:" + strings.Join(tags, "|") + ":"
+
+	flight, _ := r.tagged.LoadOrStore(namespace, &inflight{namespace: namespace})
+	return flight.(*inflight)
+}
+
+func (r *repository) inflight() *inflight {
+	return r.flight
+}
+
+func (r *repository) get(ctx context.Context, key string, dest any) (string, error) {
+	err := r.Store.Get(ctx, key, dest)
+	switch {
+	case err == nil:
+		r.dispatch(&CacheHit{Store: r.opts.name, Key: key})
+		return resultHit, nil
+	case errors.Is(err, ErrNotFound):
+		r.dispatch(&CacheMissed{Store: r.opts.name, Key: key})
+		return resultMiss, err
+	default:
+		return resultError, err
+	}
+}
+
+func (r *repository) put(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	ok, err := r.Store.Put(ctx, key, value, ttl)
+	if err == nil && ok {
+		r.dispatch(&KeyWritten{Store: r.opts.name, Key: key, TTL: ttl})
+	}
+	return ok, err
+}
+
+func (r *repository) forget(ctx context.Context, key string) (bool, error) {
+	ok, err := r.Store.Forget(ctx, key)
+	if err == nil && ok {
+		r.dispatch(&KeyForgotten{Store: r.opts.name, Key: key})
+	}
+	return ok, err
+}
+
+func (r *repository) dispatch(e event.Event) {
+	if r.opts.dispatcher != nil {
+		r.opts.dispatcher.Dispatch(e)
+	}
+}
+
+// instrument calls fn with the span, metrics of the operation.
+// The fn returns the result of the operation, which is one of hit, miss, success and error.
+func (r *repository) instrument(
+	ctx context.Context, operation, key string, fn func(context.Context) (string, error),
+) error {
+	if r.tracer == nil && r.requests == nil {
+		_, err := fn(ctx)
+		return err
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("cache.store", r.opts.name),
+		attribute.String("cache.operation", operation),
+	}
+
+	var span trace.Span
+	if r.tracer != nil {
+		ctx, span = r.tracer.Start(ctx, "cache."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		if key != "" {
+			span.SetAttributes(attribute.String("cache.key", key))
+		}
+	}
+
+	starting := time.Now()
+	result, err := fn(ctx)
+	if err != nil && result != resultMiss {
+		result = resultError
+	}
+
+	if span != nil {
+		span.SetAttributes(attribute.String("cache.result", result))
+		if result == resultError {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+
+	if r.requests != nil {
+		r.requests.Add(ctx, 1, metric.WithAttributes(append(attrs, attribute.String("cache.result", result))...))
+		r.duration.Record(ctx, time.Since(starting).Seconds(), metric.WithAttributes(attrs...))
+	}
+
+	return err
+}
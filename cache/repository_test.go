@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -79,4 +80,31 @@ func TestRepository(t *testing.T) {
 	set, err := repo.Set(ctx, "test", "test", 0)
 	assert.Nil(t, err)
 	assert.True(t, set)
+
+	// Many
+	dest := make(map[string]string)
+	assert.Nil(t, repo.Many(ctx, []string{"test"}, dest))
+	assert.Equal(t, map[string]string{"test": ""}, dest) // because of NullStore
+	assert.ErrorIs(t, repo.Many(ctx, []string{"test"}, nil), ErrInvalidDest)
+
+	// PutMany
+	putMany, err := repo.PutMany(ctx, map[string]any{"test": "test"}, 0)
+	assert.Nil(t, err)
+	assert.True(t, putMany)
+
+	// ForgetMany
+	forgetMany, err := repo.ForgetMany(ctx, "test", "test2")
+	assert.Nil(t, err)
+	assert.True(t, forgetMany)
+}
+
+func TestRepository_Tags(t *testing.T) {
+	repo := NewRepository(&NullStore{})
+
+	tagged := repo.Tags("test")
+	_, err := tagged.Put(context.Background(), "test", 1, time.Second)
+	assert.ErrorIs(t, err, ErrNotTaggable)
+	assert.ErrorIs(t, tagged.Get(context.Background(), "test", new(int)), ErrNotTaggable)
+	assert.ErrorIs(t, tagged.Many(context.Background(), []string{"test"}, map[string]int{}), ErrNotTaggable)
+	assert.ErrorIs(t, tagged.Lock("test", time.Second).Try(context.Background(), func() {}), ErrNotTaggable)
 }
@@ -8,7 +8,11 @@ import (
 	"github.com/go-kratos-ecosystem/components/v2/locker"
 )
 
-var ErrNotFound = errors.New("cache: the key is not found")
+var (
+	ErrNotFound    = errors.New("cache: the key is not found")
+	ErrInvalidDest = errors.New("cache: the dest must be a non-nil map with string keys")
+	ErrNotTaggable = errors.New("cache: the store does not support tagging")
+)
 
 type Store interface {
 	Locker
@@ -55,6 +59,33 @@ type Addable interface {
 	Add(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
 }
 
+type Expirable interface {
+	// TTL returns the remaining ttl of the key, 0 means the key never expires.
+	// If the key does not exist, the return error will be ErrNotFound.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+type Bulkable interface {
+	// Many retrieves the values of the keys from the cache.
+	// The dest must be a non-nil map[string]T, the found values will be unmarshaled and set into it,
+	// and the keys which do not exist will be skipped.
+	Many(ctx context.Context, keys []string, dest any) error
+
+	// PutMany stores the values into the cache with an expiration time.
+	PutMany(ctx context.Context, values map[string]any, ttl time.Duration) (bool, error)
+
+	// ForgetMany removes the keys from the cache.
+	// If any of the keys is removed, the return value will be true.
+	ForgetMany(ctx context.Context, keys ...string) (bool, error)
+}
+
+type Taggable interface {
+	// Tags returns a store whose keys are recorded under the given tags.
+	// The keys are only visible to the store with the same tags,
+	// and Flush only removes the keys recorded under the tags.
+	Tags(names ...string) Store
+}
+
 type Locker interface {
 	Lock(key string, ttl time.Duration) locker.Locker
 }
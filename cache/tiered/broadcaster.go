@@ -0,0 +1,81 @@
+package tiered
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Message is the invalidation message broadcast to all the instances.
+type Message struct {
+	// Instance is the identifier of the instance which sent the message.
+	Instance string `json:"instance"`
+
+	// Keys are the keys to be invalidated.
+	Keys []string `json:"keys,omitempty"`
+
+	// Flush indicates that all the keys should be invalidated.
+	Flush bool `json:"flush,omitempty"`
+}
+
+type Broadcaster interface {
+	// Broadcast sends the message to all the subscribers.
+	Broadcast(ctx context.Context, msg *Message) error
+
+	// Subscribe calls the handler for each received message.
+	// It blocks until the context is done.
+	Subscribe(ctx context.Context, handler func(msg *Message)) error
+}
+
+// RedisBroadcaster broadcasts the messages over the Redis pub/sub.
+type RedisBroadcaster struct {
+	redis   redis.UniversalClient
+	channel string
+}
+
+var _ Broadcaster = (*RedisBroadcaster)(nil)
+
+func NewRedisBroadcaster(redis redis.UniversalClient, channel string) *RedisBroadcaster {
+	return &RedisBroadcaster{
+		redis:   redis,
+		channel: channel,
+	}
+}
+
+func (b *RedisBroadcaster) Broadcast(ctx context.Context, msg *Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return b.redis.Publish(ctx, b.channel, payload).Err()
+}
+
+func (b *RedisBroadcaster) Subscribe(ctx context.Context, handler func(msg *Message)) error {
+	sub := b.redis.Subscribe(ctx, b.channel)
+	defer sub.Close()
+
+	// wait for the subscription to be confirmed
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case m, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			var msg Message
+			if err := json.Unmarshal([]byte(m.Payload), &msg); err != nil {
+				continue
+			}
+			handler(&msg)
+		}
+	}
+}
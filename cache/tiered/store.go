@@ -0,0 +1,280 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// Store is a two-level cache store.
+// The local store (L1) is checked first, and the remote store (L2) is the source of truth.
+// When the broadcaster is set, the writes on any instance invalidate the local store of all instances.
+type Store struct {
+	local  cache.Store
+	remote cache.Store
+
+	instance string
+	cancel   context.CancelFunc
+	done     chan struct{}
+
+	opts *options
+}
+
+type options struct {
+	ttl         time.Duration
+	broadcaster Broadcaster
+}
+
+type Option func(*options)
+
+// TTL sets the maximum ttl of the items in the local store.
+func TTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithBroadcaster sets the broadcaster which delivers the invalidation messages to all the instances.
+func WithBroadcaster(broadcaster Broadcaster) Option {
+	return func(o *options) {
+		o.broadcaster = broadcaster
+	}
+}
+
+var (
+	_ cache.Store   = (*Store)(nil)
+	_ cache.Addable = (*Store)(nil)
+)
+
+func New(local, remote cache.Store, opts ...Option) *Store {
+	opt := &options{
+		ttl: time.Second * 10, //nolint:mnd
+	}
+
+	for _, o := range opts {
+		o(opt)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Store{
+		local:    local,
+		remote:   remote,
+		instance: uuid.New().String(),
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		opts:     opt,
+	}
+
+	go s.subscribe(ctx)
+
+	return s
+}
+
+func (s *Store) Has(ctx context.Context, key string) (bool, error) {
+	if ok, err := s.local.Has(ctx, key); err == nil && ok {
+		return true, nil
+	}
+
+	return s.remote.Has(ctx, key)
+}
+
+func (s *Store) Get(ctx context.Context, key string, dest any) error {
+	if err := s.local.Get(ctx, key, dest); err == nil {
+		return nil
+	}
+
+	if err := s.remote.Get(ctx, key, dest); err != nil {
+		return err
+	}
+
+	// the local item must not outlive the remote one
+	ttl := s.opts.ttl
+	if store, ok := s.remote.(cache.Expirable); ok {
+		remaining, err := store.TTL(ctx, key)
+		if err != nil {
+			return nil
+		}
+		ttl = s.localTTL(remaining)
+	}
+
+	_, _ = s.local.Put(ctx, key, dest, ttl)
+
+	return nil
+}
+
+func (s *Store) Put(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	ok, err := s.remote.Put(ctx, key, value, ttl)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	return true, s.fill(ctx, key, value, s.localTTL(ttl))
+}
+
+func (s *Store) Increment(ctx context.Context, key string, value int) (int, error) {
+	v, err := s.remote.Increment(ctx, key, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return v, s.invalidate(ctx, key)
+}
+
+func (s *Store) Decrement(ctx context.Context, key string, value int) (int, error) {
+	v, err := s.remote.Decrement(ctx, key, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return v, s.invalidate(ctx, key)
+}
+
+func (s *Store) Forever(ctx context.Context, key string, value any) (bool, error) {
+	ok, err := s.remote.Forever(ctx, key, value)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	return true, s.fill(ctx, key, value, s.opts.ttl)
+}
+
+func (s *Store) Forget(ctx context.Context, key string) (bool, error) {
+	ok, err := s.remote.Forget(ctx, key)
+	if err != nil {
+		return false, err
+	}
+
+	return ok, s.invalidate(ctx, key)
+}
+
+func (s *Store) Flush(ctx context.Context) (bool, error) {
+	ok, err := s.remote.Flush(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := s.local.Flush(ctx); err != nil {
+		return false, err
+	}
+
+	return ok, s.broadcast(ctx, &Message{Flush: true})
+}
+
+func (s *Store) GetPrefix() string {
+	return s.remote.GetPrefix()
+}
+
+func (s *Store) Add(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	var (
+		ok  bool
+		err error
+	)
+	if store, addable := s.remote.(cache.Addable); addable {
+		ok, err = store.Add(ctx, key, value, ttl)
+	} else if had, e := s.remote.Has(ctx, key); e != nil {
+		err = e
+	} else if !had {
+		ok, err = s.remote.Put(ctx, key, value, ttl)
+	}
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return true, s.fill(ctx, key, value, s.localTTL(ttl))
+}
+
+// Lock returns the locker of the remote store, so the lock is shared by all the instances.
+func (s *Store) Lock(key string, ttl time.Duration) locker.Locker {
+	return s.remote.Lock(key, ttl)
+}
+
+// Close stops receiving the invalidation messages.
+func (s *Store) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// fill stores the value written to the remote store into the local store, and broadcasts the invalidation.
+// The invalidation is broadcast even if the local store fails, since the remote store has been changed.
+func (s *Store) fill(ctx context.Context, key string, value any, ttl time.Duration) error {
+	_, err := s.local.Put(ctx, key, value, ttl)
+	if err != nil {
+		// the stale item must not be served
+		_, _ = s.local.Forget(ctx, key)
+	}
+
+	return errors.Join(err, s.broadcast(ctx, &Message{Keys: []string{key}}))
+}
+
+func (s *Store) invalidate(ctx context.Context, key string) error {
+	if _, err := s.local.Forget(ctx, key); err != nil {
+		return err
+	}
+
+	return s.broadcast(ctx, &Message{Keys: []string{key}})
+}
+
+func (s *Store) broadcast(ctx context.Context, msg *Message) error {
+	if s.opts.broadcaster == nil {
+		return nil
+	}
+
+	msg.Instance = s.instance
+
+	return s.opts.broadcaster.Broadcast(ctx, msg)
+}
+
+func (s *Store) subscribe(ctx context.Context) {
+	defer close(s.done)
+
+	if s.opts.broadcaster == nil {
+		return
+	}
+
+	for {
+		err := s.opts.broadcaster.Subscribe(ctx, func(msg *Message) {
+			s.receive(ctx, msg)
+		})
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			return
+		}
+
+		// resubscribe after a while, the local store is flushed
+		// because the messages may be lost during the disconnection.
+		_, _ = s.local.Flush(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (s *Store) receive(ctx context.Context, msg *Message) {
+	if msg.Instance == s.instance {
+		return
+	}
+
+	if msg.Flush {
+		_, _ = s.local.Flush(ctx)
+		return
+	}
+
+	for _, key := range msg.Keys {
+		_, _ = s.local.Forget(ctx, key)
+	}
+}
+
+func (s *Store) localTTL(ttl time.Duration) time.Duration {
+	if ttl > 0 && ttl < s.opts.ttl {
+		return ttl
+	}
+	return s.opts.ttl
+}
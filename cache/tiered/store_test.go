@@ -0,0 +1,197 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/cache"
+	"github.com/go-kratos-ecosystem/components/v2/cache/memory"
+	redisStore "github.com/go-kratos-ecosystem/components/v2/cache/redis"
+)
+
+var ctx = context.Background()
+
+func createRedis(t *testing.T) redis.UniversalClient {
+	client := redis.NewClient(&redis.Options{
+		Addr: ":6379",
+	})
+	t.Cleanup(func() {
+		client.FlushAll(ctx)
+		_ = client.Close()
+	})
+	return client
+}
+
+func createStore(t *testing.T, client redis.UniversalClient) *Store {
+	local := memory.New()
+	store := New(local, redisStore.New(client, redisStore.Prefix("cache:tiered")),
+		TTL(time.Minute),
+		WithBroadcaster(NewRedisBroadcaster(client, "cache:tiered:invalidation")),
+	)
+	t.Cleanup(func() {
+		_ = store.Close()
+		_ = local.Close()
+	})
+	return store
+}
+
+func TestTiered_Base(t *testing.T) {
+	client := createRedis(t)
+	store := createStore(t, client)
+
+	ok1, err := store.Put(ctx, "test", "test", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok1)
+
+	var v string
+	assert.NoError(t, store.Get(ctx, "test", &v))
+	assert.Equal(t, "test", v)
+
+	// the local store still serves the value
+	assert.NoError(t, client.Del(ctx, "cache:tiered:test").Err())
+	ok2, err := store.Has(ctx, "test")
+	assert.NoError(t, err)
+	assert.True(t, ok2)
+
+	ok3, err := store.Forget(ctx, "test")
+	assert.NoError(t, err)
+	assert.False(t, ok3)
+
+	err = store.Get(ctx, "test", &v)
+	assert.True(t, errors.Is(err, cache.ErrNotFound))
+
+	ok4, err := store.Add(ctx, "test:add", "test", time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok4)
+
+	ok5, err := store.Add(ctx, "test:add", "test", time.Second)
+	assert.NoError(t, err)
+	assert.False(t, ok5)
+
+	v1, err := store.Increment(ctx, "test:inc", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, v1)
+
+	v2, err := store.Decrement(ctx, "test:inc", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v2)
+
+	assert.Equal(t, "cache:tiered:", store.GetPrefix())
+	assert.NotNil(t, store.Lock("test:lock", time.Second))
+}
+
+func TestTiered_Invalidation(t *testing.T) {
+	client := createRedis(t)
+	store1 := createStore(t, client)
+	store2 := createStore(t, client)
+
+	// wait for the subscriptions
+	time.Sleep(time.Millisecond * 100)
+
+	_, err := store1.Put(ctx, "test:invalidation", "v1", 0)
+	assert.NoError(t, err)
+
+	// warm up the local store of store2
+	var v string
+	assert.NoError(t, store2.Get(ctx, "test:invalidation", &v))
+	assert.Equal(t, "v1", v)
+
+	// put
+	_, err = store1.Put(ctx, "test:invalidation", "v2", 0)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 100)
+
+	assert.NoError(t, store2.Get(ctx, "test:invalidation", &v))
+	assert.Equal(t, "v2", v)
+
+	// increment
+	_, err = store1.Forever(ctx, "test:invalidation:inc", 1)
+	assert.NoError(t, err)
+
+	var i int
+	assert.NoError(t, store2.Get(ctx, "test:invalidation:inc", &i))
+	assert.Equal(t, 1, i)
+
+	_, err = store1.Increment(ctx, "test:invalidation:inc", 1)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 100)
+
+	assert.NoError(t, store2.Get(ctx, "test:invalidation:inc", &i))
+	assert.Equal(t, 2, i)
+
+	// flush
+	_, err = store1.Flush(ctx)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 100)
+
+	ok, err := store2.Has(ctx, "test:invalidation")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestTiered_RemoteTTL(t *testing.T) {
+	client := createRedis(t)
+	local := memory.New()
+	store := New(local, redisStore.New(client, redisStore.Prefix("cache:tiered")), TTL(time.Minute))
+	t.Cleanup(func() {
+		_ = store.Close()
+		_ = local.Close()
+	})
+
+	assert.NoError(t, client.Set(ctx, "cache:tiered:test:ttl", `"v"`, time.Second*5).Err())
+
+	var v string
+	assert.NoError(t, store.Get(ctx, "test:ttl", &v))
+	assert.Equal(t, "v", v)
+
+	// the local item expires with the remote one
+	ttl, err := local.TTL(ctx, "test:ttl")
+	assert.NoError(t, err)
+	assert.True(t, ttl > time.Second*4 && ttl <= time.Second*5)
+}
+
+type failedStore struct {
+	*memory.Store
+}
+
+func (s *failedStore) Put(context.Context, string, any, time.Duration) (bool, error) {
+	return false, errors.New("failed")
+}
+
+func TestTiered_LocalFailed(t *testing.T) {
+	client := createRedis(t)
+	store1 := createStore(t, client)
+
+	local := &failedStore{Store: memory.New()}
+	store2 := New(local, redisStore.New(client, redisStore.Prefix("cache:tiered")),
+		TTL(time.Minute),
+		WithBroadcaster(NewRedisBroadcaster(client, "cache:tiered:invalidation")),
+	)
+	t.Cleanup(func() {
+		_ = store2.Close()
+		_ = local.Close()
+	})
+
+	// wait for the subscriptions
+	time.Sleep(time.Millisecond * 100)
+
+	_, err := store1.Put(ctx, "test:failed", "v1", 0)
+	assert.NoError(t, err)
+
+	var v string
+	assert.NoError(t, store1.Get(ctx, "test:failed", &v))
+
+	// the remote store is written, and the invalidation is still broadcast
+	ok, err := store2.Put(ctx, "test:failed", "v2", 0)
+	assert.Error(t, err)
+	assert.True(t, ok)
+	time.Sleep(time.Millisecond * 100)
+
+	assert.NoError(t, store1.Get(ctx, "test:failed", &v))
+	assert.Equal(t, "v2", v)
+}
@@ -0,0 +1,160 @@
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+)
+
+type Algorithm byte
+
+const (
+	Gzip  = Algorithm(codec.KindGzip)
+	Flate = Algorithm(codec.KindFlate)
+)
+
+// Codec compresses the values encoded by the wrapped codec, when they are not smaller than the threshold.
+// The values are read back whichever algorithm wrote them, and the plain values are passed through.
+type Codec struct {
+	codec codec.Codec
+	opts  *options
+}
+
+type options struct {
+	algorithm Algorithm
+	level     int
+	threshold int
+}
+
+type Option func(*options)
+
+// WithAlgorithm sets the compression algorithm, the default is Gzip.
+func WithAlgorithm(algorithm Algorithm) Option {
+	return func(o *options) {
+		o.algorithm = algorithm
+	}
+}
+
+// WithLevel sets the compression level, the default is flate.DefaultCompression.
+func WithLevel(level int) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// WithThreshold sets the minimum size of the value to compress, the default is 1KB.
+func WithThreshold(threshold int) Option {
+	return func(o *options) {
+		o.threshold = threshold
+	}
+}
+
+var _ codec.Codec = (*Codec)(nil)
+
+func New(c codec.Codec, opts ...Option) *Codec {
+	opt := &options{
+		algorithm: Gzip,
+		level:     flate.DefaultCompression,
+		threshold: 1024, //nolint:mnd
+	}
+
+	for _, o := range opts {
+		o(opt)
+	}
+
+	return &Codec{
+		codec: c,
+		opts:  opt,
+	}
+}
+
+func (c *Codec) Marshal(data any) ([]byte, error) {
+	src, err := c.codec.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(src) < c.opts.threshold {
+		return src, nil
+	}
+
+	compressed, err := c.compress(src)
+	if err != nil {
+		return nil, err
+	}
+
+	// not worth it
+	if len(compressed) >= len(src) {
+		return src, nil
+	}
+
+	return compressed, nil
+}
+
+func (c *Codec) Unmarshal(src []byte, dest any) error {
+	kind, payload, ok := codec.ParseHeader(src)
+	if !ok || (kind != codec.KindGzip && kind != codec.KindFlate) {
+		return c.codec.Unmarshal(src, dest)
+	}
+
+	decompressed, err := decompress(Algorithm(kind), payload)
+	if err != nil {
+		return err
+	}
+
+	return c.codec.Unmarshal(decompressed, dest)
+}
+
+func (c *Codec) compress(src []byte) ([]byte, error) {
+	var (
+		buf bytes.Buffer
+		w   io.WriteCloser
+		err error
+	)
+
+	buf.Write([]byte{codec.Marker, byte(c.opts.algorithm)})
+
+	switch c.opts.algorithm {
+	case Gzip:
+		w, err = gzip.NewWriterLevel(&buf, c.opts.level)
+	case Flate:
+		w, err = flate.NewWriter(&buf, c.opts.level)
+	default:
+		err = fmt.Errorf("compress: unknown algorithm %d", c.opts.algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(src); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decompress(algorithm Algorithm, src []byte) ([]byte, error) {
+	var r io.ReadCloser
+
+	switch algorithm {
+	case Gzip:
+		gr, err := gzip.NewReader(bytes.NewReader(src))
+		if err != nil {
+			return nil, err
+		}
+		r = gr
+	default:
+		r = flate.NewReader(bytes.NewReader(src))
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
@@ -0,0 +1,70 @@
+package compress
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+	"github.com/go-kratos-ecosystem/components/v2/codec/json"
+	"github.com/go-kratos-ecosystem/components/v2/codec/msgpack"
+)
+
+func TestCodec(t *testing.T) {
+	large := strings.Repeat("test", 1024)
+
+	for _, algorithm := range []Algorithm{Gzip, Flate} {
+		c := New(json.Codec, WithAlgorithm(algorithm), WithThreshold(100))
+
+		// below the threshold
+		bytes1, err := c.Marshal("test")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte(`"test"`), bytes1)
+
+		var dest1 string
+		assert.NoError(t, c.Unmarshal(bytes1, &dest1))
+		assert.Equal(t, "test", dest1)
+
+		// above the threshold
+		bytes2, err := c.Marshal(large)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{codec.Marker, byte(algorithm)}, bytes2[:2])
+		assert.Less(t, len(bytes2), len(large))
+
+		var dest2 string
+		assert.NoError(t, c.Unmarshal(bytes2, &dest2))
+		assert.Equal(t, large, dest2)
+	}
+}
+
+func TestCodec_Compatible(t *testing.T) {
+	large := strings.Repeat("test", 1024)
+
+	// the plain values written before
+	plain, err := msgpack.Codec.Marshal(large)
+	assert.NoError(t, err)
+
+	var dest1 string
+	assert.NoError(t, New(msgpack.Codec).Unmarshal(plain, &dest1))
+	assert.Equal(t, large, dest1)
+
+	// the values written by another algorithm
+	bytes, err := New(msgpack.Codec, WithAlgorithm(Flate)).Marshal(large)
+	assert.NoError(t, err)
+
+	var dest2 string
+	assert.NoError(t, New(msgpack.Codec, WithAlgorithm(Gzip)).Unmarshal(bytes, &dest2))
+	assert.Equal(t, large, dest2)
+}
+
+func TestCodec_Error(t *testing.T) {
+	_, err := New(json.Codec, WithAlgorithm(Algorithm(100)), WithThreshold(0)).Marshal("test")
+	assert.Error(t, err)
+
+	_, err = New(json.Codec, WithLevel(100), WithThreshold(0)).Marshal("test")
+	assert.Error(t, err)
+
+	var dest string
+	assert.Error(t, New(json.Codec).Unmarshal([]byte{codec.Marker, codec.KindGzip, 't'}, &dest))
+}
@@ -0,0 +1,54 @@
+package encrypt
+
+import (
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+	"github.com/go-kratos-ecosystem/components/v2/encrypter"
+)
+
+// Codec encrypts the values encoded by the wrapped codec with the encrypter.
+// The plain values written before are passed through, so they stay readable.
+//
+// To compress the values as well, wrap the compress codec, since the encrypted values hardly compress:
+//
+//	encrypt.New(compress.New(json.Codec), encrypter.New(key))
+type Codec struct {
+	codec     codec.Codec
+	encrypter *encrypter.Encrypter
+}
+
+var _ codec.Codec = (*Codec)(nil)
+
+func New(c codec.Codec, e *encrypter.Encrypter) *Codec {
+	return &Codec{
+		codec:     c,
+		encrypter: e,
+	}
+}
+
+func (c *Codec) Marshal(data any) ([]byte, error) {
+	src, err := c.codec.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := c.encrypter.Encrypt(string(src))
+	if err != nil {
+		return nil, err
+	}
+
+	return codec.WithHeader(codec.KindEncrypted, []byte(ciphertext)), nil
+}
+
+func (c *Codec) Unmarshal(src []byte, dest any) error {
+	kind, payload, ok := codec.ParseHeader(src)
+	if !ok || kind != codec.KindEncrypted {
+		return c.codec.Unmarshal(src, dest)
+	}
+
+	plaintext, err := c.encrypter.Decrypt(string(payload))
+	if err != nil {
+		return err
+	}
+
+	return c.codec.Unmarshal([]byte(plaintext), dest)
+}
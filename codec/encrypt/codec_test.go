@@ -0,0 +1,53 @@
+package encrypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+	"github.com/go-kratos-ecosystem/components/v2/codec/compress"
+	"github.com/go-kratos-ecosystem/components/v2/codec/json"
+	"github.com/go-kratos-ecosystem/components/v2/encrypter"
+)
+
+var key = "EAFBSPAXDCIOGRUVNERQGXPYGPNKYATM"
+
+func TestCodec(t *testing.T) {
+	c := New(json.Codec, encrypter.New(key))
+
+	bytes, err := c.Marshal(map[string]string{"foo": "bar"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{codec.Marker, codec.KindEncrypted}, bytes[:2])
+	assert.NotContains(t, string(bytes), "bar")
+
+	var dest map[string]string
+	assert.NoError(t, c.Unmarshal(bytes, &dest))
+	assert.Equal(t, map[string]string{"foo": "bar"}, dest)
+
+	// the plain values written before
+	var plain map[string]string
+	assert.NoError(t, c.Unmarshal([]byte(`{"foo":"baz"}`), &plain))
+	assert.Equal(t, map[string]string{"foo": "baz"}, plain)
+}
+
+func TestCodec_Compress(t *testing.T) {
+	c := New(compress.New(json.Codec, compress.WithThreshold(0)), encrypter.New(key))
+
+	bytes, err := c.Marshal("testtesttesttesttesttesttesttest")
+	assert.NoError(t, err)
+
+	var dest string
+	assert.NoError(t, c.Unmarshal(bytes, &dest))
+	assert.Equal(t, "testtesttesttesttesttesttesttest", dest)
+}
+
+func TestCodec_Error(t *testing.T) {
+	_, err := New(json.Codec, encrypter.New("invalid")).Marshal("test")
+	assert.Error(t, err)
+
+	var dest string
+	assert.Error(t, New(json.Codec, encrypter.New(key)).Unmarshal(
+		codec.WithHeader(codec.KindEncrypted, []byte("!")), &dest,
+	))
+}
@@ -0,0 +1,30 @@
+package codec
+
+// Marker is the first byte of the values written by the codec decorators.
+//
+// 0xc1 is never used by msgpack and is not a valid start of JSON, so the plain
+// values written before the decorators are applied can be told apart.
+const Marker byte = 0xc1
+
+// The kinds of the header, which is the second byte of the decorated values.
+const (
+	KindGzip byte = iota + 1
+	KindFlate
+	KindEncrypted
+)
+
+// WithHeader prepends the header of the kind to the payload.
+func WithHeader(kind byte, payload []byte) []byte {
+	dst := make([]byte, 0, len(payload)+2) //nolint:mnd
+	dst = append(dst, Marker, kind)
+	return append(dst, payload...)
+}
+
+// ParseHeader returns the kind and the payload of the value.
+// If the value has no header, ok will be false and the value is plain.
+func ParseHeader(src []byte) (kind byte, payload []byte, ok bool) {
+	if len(src) < 2 || src[0] != Marker { //nolint:mnd
+		return 0, src, false
+	}
+	return src[1], src[2:], true
+}
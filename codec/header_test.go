@@ -0,0 +1,26 @@
+package codec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeader(t *testing.T) {
+	src := WithHeader(KindGzip, []byte("test"))
+	assert.Equal(t, []byte{Marker, KindGzip, 't', 'e', 's', 't'}, src)
+
+	kind, payload, ok := ParseHeader(src)
+	assert.True(t, ok)
+	assert.Equal(t, KindGzip, kind)
+	assert.Equal(t, []byte("test"), payload)
+
+	// plain
+	kind, payload, ok = ParseHeader([]byte(`"test"`))
+	assert.False(t, ok)
+	assert.Zero(t, kind)
+	assert.Equal(t, []byte(`"test"`), payload)
+
+	_, _, ok = ParseHeader([]byte{Marker})
+	assert.False(t, ok)
+}
@@ -0,0 +1,209 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrExpired is returned by the futures of the promises which have been removed by the ttl.
+var ErrExpired = errors.New("coordinator: the promise has expired")
+
+// Promise is the writer side of the one-shot result, which is set once by Resolve or Reject.
+type Promise[T any] struct {
+	done  chan struct{}
+	once  sync.Once
+	value T
+	err   error
+
+	settled func() // called once the promise is settled
+}
+
+// Future is the reader side of the one-shot result.
+type Future[T any] struct {
+	promise *Promise[T]
+}
+
+func NewPromise[T any]() *Promise[T] {
+	return &Promise[T]{
+		done: make(chan struct{}),
+	}
+}
+
+// Resolve sets the value, it returns false if the promise has been settled.
+func (p *Promise[T]) Resolve(value T) bool {
+	return p.settle(value, nil)
+}
+
+// Reject sets the error, it returns false if the promise has been settled.
+func (p *Promise[T]) Reject(err error) bool {
+	var zero T
+	return p.settle(zero, err)
+}
+
+// Future returns the future of the promise.
+func (p *Promise[T]) Future() *Future[T] {
+	return &Future[T]{promise: p}
+}
+
+func (p *Promise[T]) settle(value T, err error) bool {
+	settled := false
+	p.once.Do(func() {
+		p.value, p.err = value, err
+		close(p.done)
+		settled = true
+
+		if p.settled != nil {
+			p.settled()
+		}
+	})
+	return settled
+}
+
+// Done returns the channel, which is closed when the promise is settled.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.promise.done
+}
+
+// Await waits for the result of the promise, or returns the error of ctx.
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.promise.done:
+		return f.promise.value, f.promise.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// PromiseManager manages the promises by the identifier, like Manager.
+// The settled promises are removed after the ttl, so the map does not grow forever.
+// The removed identifiers are remembered for another ttl, in which the promises of them
+// are rejected with ErrExpired, instead of the new ones which would never be settled.
+type PromiseManager[T any] struct {
+	promises map[string]*Promise[T]
+	expired  map[string]time.Time // the deadline of the removed identifiers
+	mu       sync.Mutex
+	ttl      time.Duration
+}
+
+type PromiseManagerOption func(*promiseManagerOptions)
+
+type promiseManagerOptions struct {
+	ttl time.Duration
+}
+
+// WithTTL sets the duration to keep the settled promises, the default is 1 minute.
+// The settled promises are kept forever if the ttl is not positive.
+func WithTTL(ttl time.Duration) PromiseManagerOption {
+	return func(o *promiseManagerOptions) {
+		o.ttl = ttl
+	}
+}
+
+func NewPromiseManager[T any](opts ...PromiseManagerOption) *PromiseManager[T] {
+	o := &promiseManagerOptions{
+		ttl: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &PromiseManager[T]{
+		promises: make(map[string]*Promise[T]),
+		expired:  make(map[string]time.Time),
+		ttl:      o.ttl,
+	}
+}
+
+// Promise returns the promise of the identifier, which is created if it does not exist.
+// If the promise has been removed by the ttl recently, the returned one is rejected with ErrExpired.
+func (m *PromiseManager[T]) Promise(identifier string) *Promise[T] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if p, ok := m.promises[identifier]; ok {
+		return p
+	}
+
+	if deadline, ok := m.expired[identifier]; ok {
+		if time.Now().Before(deadline) {
+			p := NewPromise[T]()
+			p.Reject(ErrExpired)
+			return p
+		}
+		delete(m.expired, identifier)
+	}
+
+	p := NewPromise[T]()
+	if m.ttl > 0 {
+		p.settled = func() {
+			time.AfterFunc(m.ttl, func() {
+				m.remove(identifier, p)
+			})
+		}
+	}
+	m.promises[identifier] = p
+
+	return p
+}
+
+// Future returns the future of the identifier.
+func (m *PromiseManager[T]) Future(identifier string) *Future[T] {
+	return m.Promise(identifier).Future()
+}
+
+// Resolve sets the value of the identifier, it returns false if the promise has been settled.
+func (m *PromiseManager[T]) Resolve(identifier string, value T) bool {
+	return m.Promise(identifier).Resolve(value)
+}
+
+// Reject sets the error of the identifier, it returns false if the promise has been settled.
+func (m *PromiseManager[T]) Reject(identifier string, err error) bool {
+	return m.Promise(identifier).Reject(err)
+}
+
+// Len returns the number of the promises.
+func (m *PromiseManager[T]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.promises)
+}
+
+// Clear removes all the promises, the unsettled ones are kept waiting.
+func (m *PromiseManager[T]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.promises = make(map[string]*Promise[T])
+	m.expired = make(map[string]time.Time)
+}
+
+// remove removes the promise of the identifier, unless it has been replaced,
+// and remembers the identifier as expired for another ttl.
+func (m *PromiseManager[T]) remove(identifier string, p *Promise[T]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.promises[identifier] != p {
+		return
+	}
+	delete(m.promises, identifier)
+
+	m.expired[identifier] = time.Now().Add(m.ttl)
+	time.AfterFunc(m.ttl, func() {
+		m.forget(identifier)
+	})
+}
+
+// forget removes the expired identifier, unless it has been expired again later.
+func (m *PromiseManager[T]) forget(identifier string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if deadline, ok := m.expired[identifier]; ok && !time.Now().Before(deadline) {
+		delete(m.expired, identifier)
+	}
+}
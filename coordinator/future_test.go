@@ -0,0 +1,121 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromise(t *testing.T) {
+	p := NewPromise[int]()
+	f := p.Future()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := f.Await(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 1, v)
+		}()
+	}
+
+	assert.True(t, p.Resolve(1))
+	assert.False(t, p.Resolve(2))
+	assert.False(t, p.Reject(errors.New("test")))
+	wg.Wait()
+
+	<-f.Done()
+	v, err := f.Await(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+}
+
+func TestPromise_Reject(t *testing.T) {
+	p := NewPromise[string]()
+
+	e := errors.New("test")
+	assert.True(t, p.Reject(e))
+
+	v, err := p.Future().Await(context.Background())
+	assert.ErrorIs(t, err, e)
+	assert.Empty(t, v)
+}
+
+func TestFuture_Context(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	v, err := NewPromise[int]().Future().Await(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, v)
+}
+
+func TestPromiseManager(t *testing.T) {
+	m := NewPromiseManager[string](WithTTL(time.Millisecond * 50))
+
+	f := m.Future("foo")
+	assert.Same(t, m.Promise("foo"), m.Promise("foo"))
+	assert.Equal(t, 1, m.Len())
+
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		assert.True(t, m.Resolve("foo", "bar"))
+	}()
+
+	v, err := f.Await(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", v)
+
+	// resolve before await
+	assert.True(t, m.Reject("baz", errors.New("test")))
+	_, err = m.Future("baz").Await(context.Background())
+	assert.Error(t, err)
+
+	// the unsettled promise is kept
+	m.Promise("qux")
+	assert.Equal(t, 3, m.Len())
+
+	// the settled promises are removed after the ttl
+	time.Sleep(time.Millisecond * 100)
+	assert.Equal(t, 1, m.Len())
+
+	m.Clear()
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestPromiseManager_Expired(t *testing.T) {
+	m := NewPromiseManager[string](WithTTL(time.Millisecond * 50))
+
+	assert.True(t, m.Resolve("foo", "bar"))
+	time.Sleep(time.Millisecond * 70)
+	assert.Equal(t, 0, m.Len())
+
+	// the late future does not wait forever
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := m.Future("foo").Await(ctx)
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.False(t, m.Resolve("foo", "baz"))
+	assert.Equal(t, 0, m.Len())
+
+	// the identifier is reusable after another ttl
+	time.Sleep(time.Millisecond * 50)
+	assert.True(t, m.Resolve("foo", "baz"))
+	v, err := m.Future("foo").Await(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "baz", v)
+}
+
+func TestPromiseManager_Forever(t *testing.T) {
+	m := NewPromiseManager[int](WithTTL(0))
+
+	assert.True(t, m.Resolve("foo", 1))
+	time.Sleep(time.Millisecond * 10)
+	assert.Equal(t, 1, m.Len())
+}
@@ -0,0 +1,248 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos-ecosystem/components/v2/coordinator"
+)
+
+// Manager is the coordinator manager shared by the processes, with the same API as coordinator.Manager.
+//
+// Close on any instance publishes the identifier through redis pub/sub, which closes the
+// coordinators of the identifier on every instance. The identifier is also persisted as
+// a closed marker, so the coordinators created after the close are closed right away.
+// The markers are checked periodically as well, in case the published messages are lost.
+type Manager struct {
+	redis   redis.UniversalClient
+	prefix  string
+	ttl     time.Duration
+	poll    time.Duration
+	timeout time.Duration
+
+	coordinators map[string]*coordinator.Coordinator
+	mu           sync.Mutex
+
+	pubsub *redis.PubSub
+	done   chan struct{}
+	wg     sync.WaitGroup
+	once   sync.Once
+}
+
+type Option func(*Manager)
+
+// WithPrefix sets the prefix of the keys and the channel, the default is "coordinator".
+func WithPrefix(prefix string) Option {
+	return func(m *Manager) {
+		m.prefix = prefix + ":"
+	}
+}
+
+// WithTTL sets the ttl of the closed markers, the default is 24 hours.
+func WithTTL(ttl time.Duration) Option {
+	return func(m *Manager) {
+		m.ttl = ttl
+	}
+}
+
+// WithPollInterval sets the interval to check the closed markers of the open coordinators,
+// the default is 5 seconds, and 0 disables it.
+func WithPollInterval(interval time.Duration) Option {
+	return func(m *Manager) {
+		m.poll = interval
+	}
+}
+
+// WithTimeout sets the timeout of the redis commands issued by Until and Close, the default is 3 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(m *Manager) {
+		m.timeout = timeout
+	}
+}
+
+// NewManager returns the manager, and subscribes to the closed identifiers until Stop is called.
+func NewManager(redis redis.UniversalClient, opts ...Option) *Manager {
+	m := &Manager{
+		redis:        redis,
+		prefix:       "coordinator:",
+		ttl:          time.Hour * 24,  //nolint:mnd
+		poll:         time.Second * 5, //nolint:mnd
+		timeout:      time.Second * 3, //nolint:mnd
+		coordinators: make(map[string]*coordinator.Coordinator),
+		done:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.pubsub = m.redis.Subscribe(context.Background(), m.channel())
+
+	m.wg.Add(1)
+	go m.subscribe()
+
+	if m.poll > 0 {
+		m.wg.Add(1)
+		go m.check()
+	}
+
+	return m
+}
+
+// Until returns the coordinator of the identifier, which is closed if the identifier has been closed on any instance.
+func (m *Manager) Until(identifier string) *coordinator.Coordinator {
+	m.mu.Lock()
+	c, ok := m.coordinators[identifier]
+	if !ok {
+		c = coordinator.NewCoordinator()
+		m.coordinators[identifier] = c
+	}
+	m.mu.Unlock()
+
+	// the coordinator is registered before checking the marker,
+	// so the close published in the meantime is not missed.
+	if !ok {
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		defer cancel()
+
+		if n, err := m.redis.Exists(ctx, m.key(identifier)).Result(); err == nil && n > 0 {
+			c.Close()
+		}
+	}
+
+	return c
+}
+
+// Close closes the coordinators of the identifier on every instance.
+// The error is ignored like coordinator.Manager, use CloseContext to handle it.
+func (m *Manager) Close(identifier string) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	_ = m.CloseContext(ctx, identifier)
+}
+
+// CloseContext closes the coordinators of the identifier on every instance.
+// The local coordinator is closed even if the redis commands fail.
+func (m *Manager) CloseContext(ctx context.Context, identifier string) error {
+	m.closeLocal(identifier, true)
+
+	// persist the marker before publishing, so the instances checking the marker after the message see it
+	if err := m.redis.Set(ctx, m.key(identifier), 1, m.ttl).Err(); err != nil {
+		return err
+	}
+
+	return m.redis.Publish(ctx, m.channel(), identifier).Err()
+}
+
+// Clear closes and removes all the local coordinators, the closed markers are kept.
+func (m *Manager) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.coordinators {
+		c.Close()
+	}
+
+	m.coordinators = make(map[string]*coordinator.Coordinator)
+}
+
+// Stop stops the subscription and the checking of the closed markers.
+func (m *Manager) Stop() error {
+	var err error
+	m.once.Do(func() {
+		close(m.done)
+		err = m.pubsub.Close()
+		m.wg.Wait()
+	})
+	return err
+}
+
+func (m *Manager) subscribe() {
+	defer m.wg.Done()
+
+	for msg := range m.pubsub.Channel() {
+		m.closeLocal(msg.Payload, false)
+	}
+}
+
+// check closes the open coordinators whose closed markers exist.
+func (m *Manager) check() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.poll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+		}
+
+		identifiers := m.open()
+		if len(identifiers) == 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		cmds := make([]*redis.IntCmd, len(identifiers))
+		_, _ = m.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, identifier := range identifiers {
+				cmds[i] = pipe.Exists(ctx, m.key(identifier))
+			}
+			return nil
+		})
+		cancel()
+
+		for i, cmd := range cmds {
+			if cmd.Val() > 0 {
+				m.closeLocal(identifiers[i], false)
+			}
+		}
+	}
+}
+
+// open returns the identifiers of the open coordinators.
+func (m *Manager) open() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	identifiers := make([]string, 0, len(m.coordinators))
+	for identifier, c := range m.coordinators {
+		select {
+		case <-c.Done():
+		default:
+			identifiers = append(identifiers, identifier)
+		}
+	}
+	return identifiers
+}
+
+// closeLocal closes the local coordinator of the identifier.
+// If create is true, the closed coordinator is created when there is none, like coordinator.Manager.
+func (m *Manager) closeLocal(identifier string, create bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.coordinators[identifier]
+	if !ok {
+		if !create {
+			return
+		}
+		c = coordinator.NewCoordinator()
+		m.coordinators[identifier] = c
+	}
+
+	c.Close()
+}
+
+func (m *Manager) key(identifier string) string {
+	return m.prefix + "closed:" + identifier
+}
+
+func (m *Manager) channel() string {
+	return m.prefix + "closed"
+}
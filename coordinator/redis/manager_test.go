@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/coordinator"
+)
+
+func newRedis(t *testing.T) redis.UniversalClient {
+	rdb := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	t.Cleanup(func() {
+		_ = rdb.FlushAll(context.Background()).Err()
+		_ = rdb.Close()
+	})
+	return rdb
+}
+
+func newManager(t *testing.T, rdb redis.UniversalClient, opts ...Option) *Manager {
+	m := NewManager(rdb, opts...)
+	t.Cleanup(func() {
+		assert.NoError(t, m.Stop())
+	})
+	return m
+}
+
+func assertDone(t *testing.T, c *coordinator.Coordinator) {
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Error("the coordinator is not closed")
+	}
+}
+
+func assertNotDone(t *testing.T, c *coordinator.Coordinator) {
+	select {
+	case <-c.Done():
+		t.Error("the coordinator is closed")
+	default:
+	}
+}
+
+func TestManager(t *testing.T) {
+	rdb := newRedis(t)
+	m1, m2 := newManager(t, rdb), newManager(t, rdb)
+
+	c1, c2 := m1.Until("foo"), m2.Until("foo")
+	assert.Same(t, c1, m1.Until("foo"))
+	assertNotDone(t, c1)
+	assertNotDone(t, c2)
+
+	time.Sleep(time.Millisecond * 50) // wait for the subscriptions
+
+	// closed on every instance
+	m1.Close("foo")
+	assertDone(t, c1)
+	assertDone(t, c2)
+
+	// the late waiters
+	assertDone(t, newManager(t, rdb).Until("foo"))
+
+	// the others are not closed
+	assertNotDone(t, m2.Until("bar"))
+}
+
+func TestManager_Poll(t *testing.T) {
+	rdb := newRedis(t)
+	m := newManager(t, rdb, WithPrefix("test"), WithPollInterval(time.Millisecond*50))
+
+	c := m.Until("foo")
+	assertNotDone(t, c)
+
+	// the published message is lost
+	assert.NoError(t, rdb.Set(context.Background(), "test:closed:foo", 1, time.Minute).Err())
+	assertDone(t, c)
+}
+
+func TestManager_CloseAndClear(t *testing.T) {
+	rdb := newRedis(t)
+	m := newManager(t, rdb, WithPollInterval(0))
+
+	// close before until
+	assert.NoError(t, m.CloseContext(context.Background(), "foo"))
+	assertDone(t, m.Until("foo"))
+
+	c := m.Until("bar")
+	m.Clear()
+	assertDone(t, c)
+	assert.NotSame(t, c, m.Until("bar"))
+
+	assert.NoError(t, m.Stop())
+	assert.NoError(t, m.Stop())
+}
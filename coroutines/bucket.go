@@ -0,0 +1,54 @@
+package coroutines
+
+import (
+	"sync"
+	"time"
+)
+
+// bucket is a token bucket, which is refilled with rate tokens per second up to burst tokens.
+type bucket struct {
+	rate  float64
+	burst float64
+
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+func newBucket(rate float64, burst int) *bucket {
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &bucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// take takes n tokens, and returns the duration to wait before the tokens are available.
+// The tokens are reserved, so the callers are served in order, and n may exceed burst by going into debt.
+func (b *bucket) take(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// wait blocks until n tokens are taken.
+func (b *bucket) wait(n int) {
+	if d := b.take(n); d > 0 {
+		time.Sleep(d)
+	}
+}
@@ -0,0 +1,332 @@
+package coroutines
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Pipeline runs the typed stages connected by the channels, e.g. Map, Filter, Batch and Merge.
+//
+// The stages share the context of the pipeline: the first error of the stages cancels it,
+// so all the stages stop, and Wait returns the error.
+// The output of the last stage must be drained, or the context must be canceled.
+//
+// Example:
+//
+//	p := coroutines.NewPipeline(ctx)
+//	users := coroutines.Map(p, coroutines.From(p, ids...), fetchUser, coroutines.WithConcurrency(10), coroutines.WithOrdered())
+//	active := coroutines.Filter(p, users, isActive)
+//	for batch := range coroutines.Batch(p, active, 100, time.Second) {
+//	  // save the batch
+//	}
+//	err := p.Wait()
+type Pipeline struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	err  error
+	once sync.Once
+	wg   sync.WaitGroup
+}
+
+func NewPipeline(ctx context.Context) *Pipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Pipeline{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Context returns the context of the pipeline, which is canceled on the first error.
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+// Wait waits for all the stages, and returns the first error of the stages, or the error of the parent context.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+
+	err := p.err
+	if err == nil {
+		err = p.ctx.Err()
+	}
+	p.cancel()
+
+	return err
+}
+
+func (p *Pipeline) fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
+
+func (p *Pipeline) goStage(fn func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		fn()
+	}()
+}
+
+// send sends the value to the channel, it returns false if the pipeline is canceled.
+func send[T any](p *Pipeline, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+// receive receives the value from the channel, ok is false if the channel is closed or the pipeline is canceled.
+func receive[T any](p *Pipeline, in <-chan T) (v T, ok bool) {
+	select {
+	case v, ok = <-in:
+		return v, ok
+	case <-p.ctx.Done():
+		return v, false
+	}
+}
+
+type stageOptions struct {
+	concurrency int
+	ordered     bool
+}
+
+type StageOption func(*stageOptions)
+
+// WithConcurrency sets the number of the goroutines of the stage, the default is 1.
+func WithConcurrency(concurrency int) StageOption {
+	return func(o *stageOptions) {
+		o.concurrency = concurrency
+	}
+}
+
+// WithOrdered keeps the order of the input in the output, when the concurrency is greater than 1.
+func WithOrdered() StageOption {
+	return func(o *stageOptions) {
+		o.ordered = true
+	}
+}
+
+// From emits the items.
+func From[T any](p *Pipeline, items ...T) <-chan T {
+	out := make(chan T)
+
+	p.goStage(func() {
+		defer close(out)
+
+		for _, item := range items {
+			if !send(p, out, item) {
+				return
+			}
+		}
+	})
+
+	return out
+}
+
+// Map emits the result of fn for each input.
+func Map[In, Out any](p *Pipeline, in <-chan In, fn func(context.Context, In) (Out, error), opts ...StageOption) <-chan Out {
+	return process(p, in, func(ctx context.Context, v In) (Out, bool, error) {
+		out, err := fn(ctx, v)
+		return out, true, err
+	}, opts...)
+}
+
+// Filter emits the input for which fn returns true.
+func Filter[T any](p *Pipeline, in <-chan T, fn func(context.Context, T) (bool, error), opts ...StageOption) <-chan T {
+	return process(p, in, func(ctx context.Context, v T) (T, bool, error) {
+		keep, err := fn(ctx, v)
+		return v, keep, err
+	}, opts...)
+}
+
+// Batch emits the input in batches of the size, or the collected ones every interval if it is positive.
+func Batch[T any](p *Pipeline, in <-chan T, size int, interval time.Duration) <-chan []T {
+	if size <= 0 {
+		size = 1
+	}
+
+	out := make(chan []T)
+
+	p.goStage(func() {
+		defer close(out)
+
+		var tick <-chan time.Time
+		if interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		batch := make([]T, 0, size)
+		flush := func() bool {
+			if len(batch) == 0 {
+				return true
+			}
+			ok := send(p, out, batch)
+			batch = make([]T, 0, size)
+			return ok
+		}
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, v)
+				if len(batch) >= size && !flush() {
+					return
+				}
+			case <-tick:
+				if !flush() {
+					return
+				}
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	})
+
+	return out
+}
+
+// Merge emits the input of all the channels, in no particular order.
+func Merge[T any](p *Pipeline, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		p.goStage(func() {
+			defer wg.Done()
+
+			for {
+				v, ok := receive(p, in)
+				if !ok || !send(p, out, v) {
+					return
+				}
+			}
+		})
+	}
+
+	p.goStage(func() {
+		wg.Wait()
+		close(out)
+	})
+
+	return out
+}
+
+// process runs fn for each input with the concurrency, and emits the kept results.
+func process[In, Out any](
+	p *Pipeline, in <-chan In, fn func(context.Context, In) (Out, bool, error), opts ...StageOption,
+) <-chan Out {
+	o := &stageOptions{
+		concurrency: 1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.concurrency <= 0 {
+		o.concurrency = 1
+	}
+
+	out := make(chan Out)
+
+	// call returns the result to emit, ok is false if the result is dropped or the pipeline fails.
+	call := func(v In) (result Out, emit bool, ok bool) {
+		result, keep, err := fn(p.ctx, v)
+		if err != nil {
+			p.fail(err)
+			return result, false, false
+		}
+		return result, keep, true
+	}
+
+	if !o.ordered || o.concurrency == 1 {
+		var wg sync.WaitGroup
+		wg.Add(o.concurrency)
+		for i := 0; i < o.concurrency; i++ {
+			p.goStage(func() {
+				defer wg.Done()
+
+				for {
+					v, ok := receive(p, in)
+					if !ok {
+						return
+					}
+					result, emit, ok := call(v)
+					if !ok || (emit && !send(p, out, result)) {
+						return
+					}
+				}
+			})
+		}
+
+		p.goStage(func() {
+			wg.Wait()
+			close(out)
+		})
+
+		return out
+	}
+
+	// ordered: the slots of the results are queued in the order of the input,
+	// and the results are emitted in the order of the slots.
+	type slot struct {
+		result Out
+		emit   bool
+		ok     bool
+	}
+	slots := make(chan chan slot, o.concurrency)
+	sem := make(chan struct{}, o.concurrency)
+
+	p.goStage(func() {
+		defer close(slots)
+
+		for {
+			v, ok := receive(p, in)
+			if !ok {
+				return
+			}
+
+			s := make(chan slot, 1)
+			if !send(p, slots, s) || !send(p, sem, struct{}{}) {
+				s <- slot{}
+				return
+			}
+
+			p.goStage(func() {
+				defer func() {
+					<-sem
+				}()
+
+				result, emit, ok := call(v)
+				s <- slot{result: result, emit: emit, ok: ok}
+			})
+		}
+	})
+
+	p.goStage(func() {
+		defer close(out)
+
+		for s := range slots {
+			r := <-s
+			if !r.ok {
+				return
+			}
+			if r.emit && !send(p, out, r.result) {
+				return
+			}
+		}
+	})
+
+	return out
+}
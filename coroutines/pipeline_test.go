@@ -0,0 +1,159 @@
+package coroutines
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func collect[T any](in <-chan T) []T {
+	var items []T
+	for v := range in {
+		items = append(items, v)
+	}
+	return items
+}
+
+func double(_ context.Context, v int) (int, error) {
+	time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond) //nolint:gosec
+	return v * 2, nil
+}
+
+func TestPipeline_Map(t *testing.T) {
+	// ordered
+	p := NewPipeline(context.Background())
+	items := collect(Map(p, From(p, 1, 2, 3, 4, 5, 6, 7, 8), double, WithConcurrency(4), WithOrdered()))
+	assert.NoError(t, p.Wait())
+	assert.Equal(t, []int{2, 4, 6, 8, 10, 12, 14, 16}, items)
+
+	// unordered
+	p = NewPipeline(context.Background())
+	items = collect(Map(p, From(p, 1, 2, 3, 4, 5, 6, 7, 8), double, WithConcurrency(4)))
+	assert.NoError(t, p.Wait())
+	sort.Ints(items)
+	assert.Equal(t, []int{2, 4, 6, 8, 10, 12, 14, 16}, items)
+}
+
+func TestPipeline_Concurrency(t *testing.T) {
+	var running, peak int64
+
+	p := NewPipeline(context.Background())
+	start := time.Now()
+	items := collect(Map(p, From(p, 1, 2, 3, 4, 5, 6), func(_ context.Context, v int) (int, error) {
+		n := atomic.AddInt64(&running, 1)
+		for {
+			old := atomic.LoadInt64(&peak)
+			if n <= old || atomic.CompareAndSwapInt64(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 50)
+		atomic.AddInt64(&running, -1)
+		return v, nil
+	}, WithConcurrency(3), WithOrdered()))
+	assert.NoError(t, p.Wait())
+
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, items)
+	assert.Equal(t, int64(3), peak)
+	assert.Less(t, time.Since(start), time.Millisecond*250)
+}
+
+func TestPipeline_Filter(t *testing.T) {
+	even := func(_ context.Context, v int) (bool, error) {
+		return v%2 == 0, nil
+	}
+
+	for _, opts := range [][]StageOption{nil, {WithConcurrency(3), WithOrdered()}} {
+		p := NewPipeline(context.Background())
+		items := collect(Filter(p, From(p, 1, 2, 3, 4, 5, 6), even, opts...))
+		assert.NoError(t, p.Wait())
+		assert.Equal(t, []int{2, 4, 6}, items)
+	}
+}
+
+func TestPipeline_Batch(t *testing.T) {
+	// by size
+	p := NewPipeline(context.Background())
+	batches := collect(Batch(p, From(p, 1, 2, 3, 4, 5), 2, 0))
+	assert.NoError(t, p.Wait())
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, batches)
+
+	// by time
+	p = NewPipeline(context.Background())
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		in <- 1
+		in <- 2
+		time.Sleep(time.Millisecond * 100)
+		in <- 3
+	}()
+	batches = collect(Batch(p, in, 10, time.Millisecond*30))
+	assert.NoError(t, p.Wait())
+	assert.Equal(t, [][]int{{1, 2}, {3}}, batches)
+}
+
+func TestPipeline_Merge(t *testing.T) {
+	p := NewPipeline(context.Background())
+	items := collect(Merge(p, From(p, 1, 2), From(p, 3), From(p, 4, 5)))
+	assert.NoError(t, p.Wait())
+
+	sort.Ints(items)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, items)
+}
+
+func TestPipeline_Error(t *testing.T) {
+	e := errors.New("test")
+
+	for _, opts := range [][]StageOption{nil, {WithConcurrency(3)}, {WithConcurrency(3), WithOrdered()}} {
+		var calls int64
+
+		p := NewPipeline(context.Background())
+		source := make(chan int)
+		go func() {
+			defer close(source)
+			for i := 0; i < 1000; i++ {
+				select {
+				case source <- i:
+				case <-p.Context().Done():
+					return
+				}
+			}
+		}()
+
+		mapped := Map(p, source, func(_ context.Context, v int) (int, error) {
+			atomic.AddInt64(&calls, 1)
+			if v == 3 {
+				return 0, e
+			}
+			return v, nil
+		}, opts...)
+		items := collect(Batch(p, Filter(p, mapped, func(context.Context, int) (bool, error) {
+			return true, nil
+		}), 100, 0))
+
+		// short-circuited, the error is propagated through the stages
+		assert.ErrorIs(t, p.Wait(), e)
+		assert.Less(t, atomic.LoadInt64(&calls), int64(1000))
+		assert.Empty(t, items)
+	}
+}
+
+func TestPipeline_Context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := NewPipeline(ctx)
+	out := Map(p, From(p, 1, 2, 3), double)
+	<-out
+	cancel()
+
+	for range out { //nolint:revive
+	}
+	assert.ErrorIs(t, p.Wait(), context.Canceled)
+}
@@ -0,0 +1,257 @@
+package coroutines
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	kerrors "github.com/go-kratos-ecosystem/components/v2/errors"
+	"github.com/go-kratos-ecosystem/components/v2/recovery"
+)
+
+var (
+	ErrPoolClosed  = errors.New("coroutines: the pool is closed")
+	ErrTaskSkipped = errors.New("coroutines: the task is skipped, since the pool is canceled")
+)
+
+// PanicError is the error of the task which panics.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("coroutines: the task panics: %v", e.Value)
+}
+
+// Result is the result of the task, the index is the order of the submission.
+type Result[T any] struct {
+	Index int
+	Value T
+	Err   error
+}
+
+type poolOptions struct {
+	size          int
+	queueSize     int
+	cancelOnError bool
+}
+
+type PoolOption func(*poolOptions)
+
+// WithPoolSize sets the number of the goroutines running the tasks, the default is runtime.GOMAXPROCS(0).
+func WithPoolSize(size int) PoolOption {
+	return func(o *poolOptions) {
+		o.size = size
+	}
+}
+
+// WithQueueSize sets the size of the queue, Go blocks when the queue is full. The default is twice the pool size.
+func WithQueueSize(size int) PoolOption {
+	return func(o *poolOptions) {
+		o.queueSize = size
+	}
+}
+
+// WithCancelOnError cancels the context of the pool on the first error,
+// the running tasks see the cancellation, and the queued tasks are skipped.
+func WithCancelOnError() PoolOption {
+	return func(o *poolOptions) {
+		o.cancelOnError = true
+	}
+}
+
+type poolTask[T any] struct {
+	index int
+	fn    func(context.Context) (T, error)
+}
+
+// Pool runs the tasks returning the results with the limited goroutines.
+//
+// Example:
+//
+//	p := coroutines.NewPool[int](ctx, coroutines.WithPoolSize(10))
+//	defer p.Close(ctx)
+//	_ = p.Go(func(ctx context.Context) (int, error) {
+//	  return 1, nil
+//	})
+//	results, err := p.Wait()
+type Pool[T any] struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	opts   *poolOptions
+
+	queue   chan poolTask[T]
+	closed  bool
+	closing chan struct{} // closed first by Close, so the blocked Go gives up
+	sendMu  sync.RWMutex  // guards the queue and closed
+
+	results []Result[T]
+	mu      sync.Mutex // guards the results
+
+	pending sync.WaitGroup // the submitted tasks
+	workers sync.WaitGroup
+	once    sync.Once
+}
+
+func NewPool[T any](ctx context.Context, opts ...PoolOption) *Pool[T] {
+	o := &poolOptions{
+		size: runtime.GOMAXPROCS(0),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.size <= 0 {
+		o.size = 1
+	}
+	if o.queueSize <= 0 {
+		o.queueSize = o.size * 2 //nolint:mnd
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	p := &Pool[T]{
+		ctx:     ctx,
+		cancel:  cancel,
+		opts:    o,
+		queue:   make(chan poolTask[T], o.queueSize),
+		closing: make(chan struct{}),
+	}
+
+	p.workers.Add(o.size)
+	for i := 0; i < o.size; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// Context returns the context of the pool, which is passed to the tasks.
+func (p *Pool[T]) Context() context.Context {
+	return p.ctx
+}
+
+// Go submits the tasks, it blocks when the queue is full.
+// If the pool is closed while blocking, the tasks not submitted are skipped and ErrPoolClosed is returned.
+func (p *Pool[T]) Go(fns ...func(context.Context) (T, error)) error {
+	p.sendMu.RLock()
+	defer p.sendMu.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	p.mu.Lock()
+	index := len(p.results)
+	p.results = append(p.results, make([]Result[T], len(fns))...)
+	p.mu.Unlock()
+
+	p.pending.Add(len(fns))
+	for i, fn := range fns {
+		select {
+		case p.queue <- poolTask[T]{index: index + i, fn: fn}:
+		case <-p.closing:
+			p.skip(index+i, index+len(fns))
+			return ErrPoolClosed
+		}
+	}
+
+	return nil
+}
+
+// skip marks the tasks in [from, to) as skipped, which are never queued.
+func (p *Pool[T]) skip(from, to int) {
+	p.mu.Lock()
+	for i := from; i < to; i++ {
+		p.results[i] = Result[T]{Index: i, Err: ErrTaskSkipped}
+	}
+	p.mu.Unlock()
+
+	p.pending.Add(from - to)
+}
+
+// Wait waits for the submitted tasks, and returns the results in the order of the submission.
+// The error is a *errors.Group of the errors of the tasks, excluding the skipped ones.
+func (p *Pool[T]) Wait() ([]Result[T], error) {
+	p.pending.Wait()
+
+	p.mu.Lock()
+	results := make([]Result[T], len(p.results))
+	copy(results, p.results)
+	p.mu.Unlock()
+
+	group := kerrors.NewGroup()
+	for _, result := range results {
+		if !errors.Is(result.Err, ErrTaskSkipped) {
+			group.Add(result.Err)
+		}
+	}
+	if group.IsNil() {
+		return results, nil
+	}
+
+	return results, group
+}
+
+// Close stops accepting the tasks, and waits for the queued and running tasks until ctx is done.
+// When ctx is done, the context of the pool is canceled, so the queued tasks are skipped.
+func (p *Pool[T]) Close(ctx context.Context) error {
+	p.once.Do(func() {
+		// wakes the blocked Go first, which holds sendMu
+		close(p.closing)
+
+		p.sendMu.Lock()
+		p.closed = true
+		close(p.queue)
+		p.sendMu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel(ErrPoolClosed)
+		return nil
+	case <-ctx.Done():
+		p.cancel(ctx.Err())
+		return ctx.Err()
+	}
+}
+
+func (p *Pool[T]) work() {
+	defer p.workers.Done()
+
+	for task := range p.queue {
+		p.run(task)
+	}
+}
+
+func (p *Pool[T]) run(task poolTask[T]) {
+	defer p.pending.Done()
+
+	result := Result[T]{Index: task.index}
+
+	if p.ctx.Err() != nil {
+		result.Err = ErrTaskSkipped
+	} else {
+		recovery.New(recovery.WithHandler(func(v any) {
+			result.Err = &PanicError{Value: v, Stack: debug.Stack()}
+		})).Wrap(func() {
+			result.Value, result.Err = task.fn(p.ctx)
+		})
+
+		if result.Err != nil && p.opts.cancelOnError {
+			p.cancel(result.Err)
+		}
+	}
+
+	p.mu.Lock()
+	p.results[task.index] = result
+	p.mu.Unlock()
+}
@@ -0,0 +1,193 @@
+package coroutines
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	kerrors "github.com/go-kratos-ecosystem/components/v2/errors"
+)
+
+func TestPool(t *testing.T) {
+	p := NewPool[int](context.Background(), WithPoolSize(2))
+
+	var running, peak int64
+	task := func(v int) func(context.Context) (int, error) {
+		return func(context.Context) (int, error) {
+			n := atomic.AddInt64(&running, 1)
+			if n > atomic.LoadInt64(&peak) {
+				atomic.StoreInt64(&peak, n)
+			}
+			time.Sleep(time.Millisecond * 20)
+			atomic.AddInt64(&running, -1)
+			return v, nil
+		}
+	}
+
+	assert.NoError(t, p.Go(task(1), task(2), task(3)))
+	assert.NoError(t, p.Go(task(4)))
+
+	results, err := p.Wait()
+	assert.NoError(t, err)
+	assert.Len(t, results, 4)
+	for i, result := range results {
+		assert.Equal(t, i, result.Index)
+		assert.Equal(t, i+1, result.Value)
+		assert.NoError(t, result.Err)
+	}
+	assert.LessOrEqual(t, peak, int64(2))
+
+	assert.NoError(t, p.Close(context.Background()))
+	assert.ErrorIs(t, p.Go(task(5)), ErrPoolClosed)
+	assert.ErrorIs(t, context.Cause(p.Context()), ErrPoolClosed)
+}
+
+func TestPool_ErrorsAndPanics(t *testing.T) {
+	p := NewPool[string](context.Background())
+	defer p.Close(context.Background()) //nolint:errcheck
+
+	e := errors.New("test")
+	assert.NoError(t, p.Go(
+		func(context.Context) (string, error) {
+			return "ok", nil
+		},
+		func(context.Context) (string, error) {
+			return "", e
+		},
+		func(context.Context) (string, error) {
+			panic("boom")
+		},
+	))
+
+	results, err := p.Wait()
+	assert.Equal(t, "ok", results[0].Value)
+	assert.ErrorIs(t, results[1].Err, e)
+
+	var panicErr *PanicError
+	assert.ErrorAs(t, results[2].Err, &panicErr)
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+
+	var group *kerrors.Group
+	assert.ErrorAs(t, err, &group)
+	assert.Equal(t, 2, group.Len())
+	assert.True(t, group.Has(e))
+}
+
+func TestPool_CancelOnError(t *testing.T) {
+	p := NewPool[int](context.Background(), WithPoolSize(1), WithCancelOnError())
+	defer p.Close(context.Background()) //nolint:errcheck
+
+	e := errors.New("test")
+	var executed int64
+	assert.NoError(t, p.Go(
+		func(context.Context) (int, error) {
+			atomic.AddInt64(&executed, 1)
+			return 0, e
+		},
+		func(context.Context) (int, error) {
+			atomic.AddInt64(&executed, 1)
+			return 1, nil
+		},
+	))
+
+	results, err := p.Wait()
+	assert.ErrorIs(t, err, e)
+	assert.ErrorIs(t, results[1].Err, ErrTaskSkipped)
+	assert.Equal(t, int64(1), executed)
+	assert.ErrorIs(t, context.Cause(p.Context()), e)
+}
+
+func TestPool_Close(t *testing.T) {
+	// drains the queued tasks
+	p := NewPool[int](context.Background(), WithPoolSize(1), WithQueueSize(10))
+
+	var executed int64
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, p.Go(func(context.Context) (int, error) {
+			time.Sleep(time.Millisecond * 10)
+			atomic.AddInt64(&executed, 1)
+			return 0, nil
+		}))
+	}
+	assert.NoError(t, p.Close(context.Background()))
+	assert.Equal(t, int64(5), executed)
+
+	// the deadline is exceeded
+	p = NewPool[int](context.Background(), WithPoolSize(1), WithQueueSize(10))
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, p.Go(func(ctx context.Context) (int, error) {
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(time.Millisecond * 50):
+				return 1, nil
+			}
+		}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	assert.ErrorIs(t, p.Close(ctx), context.DeadlineExceeded)
+
+	results, err := p.Wait()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, results[0].Err, context.Canceled)
+	for _, result := range results[1:] {
+		assert.ErrorIs(t, result.Err, ErrTaskSkipped)
+	}
+}
+
+func TestPool_CloseFullQueue(t *testing.T) {
+	p := NewPool[int](context.Background(), WithPoolSize(1), WithQueueSize(1))
+
+	release := make(chan struct{})
+	task := func(ctx context.Context) (int, error) {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-release:
+			return 1, nil
+		}
+	}
+
+	// the running one and the queued one
+	assert.NoError(t, p.Go(task))
+	assert.NoError(t, p.Go(task))
+
+	// blocked by the full queue
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- p.Go(task, task)
+	}()
+	time.Sleep(time.Millisecond * 20)
+
+	// the expired ctx returns right away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- p.Close(ctx)
+	}()
+
+	select {
+	case err := <-closed:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("Close is blocked by Go")
+	}
+	assert.ErrorIs(t, <-blocked, ErrPoolClosed)
+	close(release)
+
+	results, err := p.Wait()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, results, 4)
+	for _, result := range results[2:] {
+		assert.ErrorIs(t, result.Err, ErrTaskSkipped)
+	}
+}
@@ -1,28 +1,144 @@
 package coroutines
 
 import (
+	"container/heap"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
+
+	"github.com/go-kratos-ecosystem/components/v2/recovery"
 )
 
+// WorkerStats is the snapshot of the tasks of the worker.
+type WorkerStats struct {
+	Queued    int64
+	Running   int64
+	Completed int64
+	Failed    int64 // the failed tasks are counted in Completed too
+}
+
+type WorkerOption func(*Worker)
+
+// WithRate limits the throughput of the worker with a token bucket,
+// which is refilled with rate tokens per second and holds up to burst tokens.
+// Each task takes the tokens of its weight before it runs.
+func WithRate(rate float64, burst int) WorkerOption {
+	return func(w *Worker) {
+		if rate > 0 {
+			w.bucket = newBucket(rate, burst)
+		}
+	}
+}
+
+// WithCapacity sets the number of the queued tasks, the pushing blocks when the queue is full.
+// The default is twice the number of the goroutines.
+func WithCapacity(capacity int) WorkerOption {
+	return func(w *Worker) {
+		w.capacity = capacity
+	}
+}
+
+// WithErrorHandler sets the handler of the errors returned by the tasks, and the panics as *PanicError.
+// Without the handler, the panics are rethrown.
+func WithErrorHandler(handler func(error)) WorkerOption {
+	return func(w *Worker) {
+		w.handler = handler
+	}
+}
+
+type workerTask struct {
+	fn       func() error
+	weight   int
+	priority int
+	seq      uint64
+}
+
+type TaskOption func(*workerTask)
+
+// WithWeight sets the number of the tokens the task takes from the rate limit, the default is 1.
+// The weight less than 1 is taken as 1.
+func WithWeight(weight int) TaskOption {
+	return func(t *workerTask) {
+		t.weight = max(weight, 1)
+	}
+}
+
+// WithPriority sets the priority of the task, the queued tasks with the higher priority run first,
+// and the tasks with the same priority run in the order of the submission. The default is 0.
+func WithPriority(priority int) TaskOption {
+	return func(t *workerTask) {
+		t.priority = priority
+	}
+}
+
+// taskQueue is the priority queue of the tasks, implementing heap.Interface.
+type taskQueue []*workerTask
+
+func (q taskQueue) Len() int { return len(q) }
+
+func (q taskQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q taskQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *taskQueue) Push(x any) { *q = append(*q, x.(*workerTask)) }
+
+func (q *taskQueue) Pop() any {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return t
+}
+
 type Worker struct {
-	fns chan func()
-	wg  sync.WaitGroup
+	queue    taskQueue
+	capacity int
+	seq      uint64
+	closed   bool
+	mu       sync.Mutex // guards the queue, seq and closed
+	pushed   *sync.Cond // signaled when a task is pushed or the worker is closed
+	popped   *sync.Cond // signaled when a task is popped or the worker is closed
+
+	bucket  *bucket
+	handler func(error)
+
+	queued, running, completed, failed atomic.Int64
+
+	wg sync.WaitGroup
 }
 
 // NewWorker creates a new worker for running tasks in parallel.
 // The max parameter specifies the maximum number of goroutines that can run at the same time.
+// The pushing blocks when the queue is full, see WithCapacity.
 //
 // Example:
 //
-//	w := coroutines.NewWorker(10)
+//	w := coroutines.NewWorker(10, coroutines.WithRate(100, 10))
 //	defer w.Close()
 //	w.Push(func() {
 //	  // do something
 //	}...)
+//	w.PushTask(func() error {
+//	  // do something
+//	}, coroutines.WithWeight(5), coroutines.WithPriority(1))
 //	w.Wait()
-func NewWorker(number int) *Worker {
+func NewWorker(number int, opts ...WorkerOption) *Worker {
 	s := &Worker{
-		fns: make(chan func(), number*2), //nolint:mnd
+		capacity: number * 2, //nolint:mnd
+	}
+	s.pushed = sync.NewCond(&s.mu)
+	s.popped = sync.NewCond(&s.mu)
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.capacity <= 0 {
+		s.capacity = 1
 	}
 
 	go s.work(number)
@@ -34,23 +150,114 @@ func (s *Worker) work(num int) {
 	ch := make(chan struct{}, num)
 	defer close(ch)
 
-	for fn := range s.fns {
+	for {
 		ch <- struct{}{}
-		go func(fn func()) {
+
+		t, ok := s.next()
+		if !ok {
+			return
+		}
+
+		if s.bucket != nil {
+			s.bucket.wait(t.weight)
+		}
+
+		s.queued.Add(-1)
+		s.running.Add(1)
+
+		go func(t *workerTask) {
 			defer func() {
+				s.running.Add(-1)
+				s.completed.Add(1)
 				<-ch
 				s.wg.Done()
 			}()
 
-			fn()
-		}(fn)
+			s.run(t)
+		}(t)
+	}
+}
+
+// next pops the task with the highest priority, it blocks until a task is pushed or the worker is closed.
+func (s *Worker) next() (*workerTask, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for len(s.queue) == 0 {
+		if s.closed {
+			return nil, false
+		}
+		s.pushed.Wait()
+	}
+
+	s.popped.Signal()
+	return heap.Pop(&s.queue).(*workerTask), true
+}
+
+func (s *Worker) run(t *workerTask) {
+	var err error
+
+	recovery.New(recovery.WithHandler(func(v any) {
+		s.failed.Add(1)
+		if s.handler == nil {
+			panic(v)
+		}
+		s.handler(&PanicError{Value: v, Stack: debug.Stack()})
+	})).Wrap(func() {
+		err = t.fn()
+	})
+
+	if err != nil {
+		s.failed.Add(1)
+		if s.handler != nil {
+			s.handler(err)
+		}
 	}
 }
 
 func (s *Worker) Push(fns ...func()) {
-	s.wg.Add(len(fns))
 	for _, fn := range fns {
-		s.fns <- fn
+		s.PushTask(func() error {
+			fn()
+			return nil
+		})
+	}
+}
+
+// PushTask pushes the task with the options, the task fails if it returns an error or panics.
+// It blocks while the queue is full, and panics if the worker is closed.
+func (s *Worker) PushTask(fn func() error, opts ...TaskOption) {
+	t := &workerTask{fn: fn, weight: 1}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for !s.closed && len(s.queue) >= s.capacity {
+		s.popped.Wait()
+	}
+	if s.closed {
+		panic("coroutines: push on the closed worker")
+	}
+
+	s.seq++
+	t.seq = s.seq
+
+	s.wg.Add(1)
+	s.queued.Add(1)
+	heap.Push(&s.queue, t)
+	s.pushed.Signal()
+}
+
+// Stats returns the live stats of the tasks.
+func (s *Worker) Stats() WorkerStats {
+	return WorkerStats{
+		Queued:    s.queued.Load(),
+		Running:   s.running.Load(),
+		Completed: s.completed.Load(),
+		Failed:    s.failed.Load(),
 	}
 }
 
@@ -58,6 +265,12 @@ func (s *Worker) Wait() {
 	s.wg.Wait()
 }
 
+// Close stops accepting the tasks, the queued tasks still run.
 func (s *Worker) Close() {
-	close(s.fns)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.pushed.Broadcast()
+	s.popped.Broadcast()
 }
@@ -1,6 +1,8 @@
 package coroutines
 
 import (
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -50,3 +52,153 @@ func TestWorker(t *testing.T) {
 	assert.True(t, time.Since(now2) > 1*time.Second)
 	assert.Len(t, ch, 0)
 }
+
+func TestWorker_Rate(t *testing.T) {
+	w := NewWorker(10, WithRate(50, 1)) //nolint:mnd
+	defer w.Close()
+
+	now := time.Now()
+	for i := 0; i < 6; i++ {
+		w.Push(func() {})
+	}
+	w.Wait()
+
+	// the first task takes the burst, the others wait 20ms each
+	assert.True(t, time.Since(now) >= 90*time.Millisecond)
+	assert.True(t, time.Since(now) < 300*time.Millisecond)
+}
+
+func TestWorker_Weight(t *testing.T) {
+	w := NewWorker(10, WithRate(100, 10)) //nolint:mnd
+	defer w.Close()
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		w.PushTask(func() error {
+			return nil
+		}, WithWeight(10))
+	}
+	w.Wait()
+
+	// each task takes the whole bucket, which is refilled in 100ms
+	assert.True(t, time.Since(now) >= 180*time.Millisecond)
+	assert.True(t, time.Since(now) < 500*time.Millisecond)
+
+	// the weight over the burst takes the tokens in debt
+	w2 := NewWorker(10, WithRate(100, 0)) //nolint:mnd
+	defer w2.Close()
+
+	now = time.Now()
+	for i := 0; i < 3; i++ {
+		w2.PushTask(func() error {
+			return nil
+		}, WithWeight(10))
+	}
+	w2.Wait()
+
+	// the first task waits 90ms for the tokens in debt, and each of the others 100ms more
+	assert.True(t, time.Since(now) >= 270*time.Millisecond)
+	assert.True(t, time.Since(now) < 600*time.Millisecond)
+
+	// the weight less than 1 takes a token
+	task := &workerTask{}
+	WithWeight(-1)(task)
+	assert.Equal(t, 1, task.weight)
+}
+
+func TestWorker_Priority(t *testing.T) {
+	var (
+		w       = NewWorker(1, WithCapacity(5))
+		blocker = make(chan struct{})
+		orders  []int
+	)
+	defer w.Close()
+
+	w.Push(func() {
+		<-blocker
+	})
+
+	for i, priority := range []int{0, 2, 1, 2, 0} {
+		w.PushTask(func() error {
+			orders = append(orders, i)
+			return nil
+		}, WithPriority(priority))
+	}
+
+	close(blocker)
+	w.Wait()
+
+	assert.Equal(t, []int{1, 3, 2, 0, 4}, orders)
+}
+
+func TestWorker_Capacity(t *testing.T) {
+	var (
+		w       = NewWorker(1)
+		blocker = make(chan struct{})
+		pushed  = make(chan struct{})
+	)
+	defer w.Close()
+
+	// the running one and the two queued ones
+	w.Push(func() { <-blocker }, func() {}, func() {})
+
+	go func() {
+		w.Push(func() {})
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("the push is not blocked by the full queue")
+	case <-time.After(time.Millisecond * 50):
+	}
+	assert.Equal(t, int64(2), w.Stats().Queued)
+
+	close(blocker)
+	<-pushed
+	w.Wait()
+	assert.Equal(t, int64(4), w.Stats().Completed)
+}
+
+func TestWorker_Stats(t *testing.T) {
+	var (
+		errs    []error
+		mu      sync.Mutex
+		blocker = make(chan struct{})
+	)
+
+	w := NewWorker(2, WithErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}))
+	defer w.Close()
+
+	w.Push(func() { <-blocker }, func() { <-blocker })
+	w.PushTask(func() error {
+		return assert.AnError
+	})
+	w.Push(func() {
+		panic("test")
+	})
+
+	assert.Eventually(t, func() bool {
+		return w.Stats() == WorkerStats{Queued: 2, Running: 2}
+	}, time.Second, time.Millisecond*10)
+
+	close(blocker)
+	w.Wait()
+
+	assert.Equal(t, WorkerStats{Completed: 4, Failed: 2}, w.Stats())
+	assert.Len(t, errs, 2)
+
+	// the two tasks run at the same time
+	var pe *PanicError
+	for _, err := range errs {
+		if !errors.Is(err, assert.AnError) {
+			assert.ErrorAs(t, err, &pe)
+		}
+	}
+	assert.NotNil(t, pe)
+	assert.Equal(t, "test", pe.Value)
+}
@@ -7,11 +7,13 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"sync"
 )
 
 type Encrypter struct {
 	key    []byte
 	cipher cipher.Block
+	mu     sync.Mutex
 }
 
 func New(key string) *Encrypter {
@@ -65,6 +67,9 @@ func (e *Encrypter) Decrypt(value string) (string, error) {
 }
 
 func (e *Encrypter) getBlock() (cipher.Block, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if e.cipher != nil {
 		return e.cipher, nil
 	}
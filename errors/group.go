@@ -66,3 +66,8 @@ func (g *Group) First() error {
 func (g *Group) IsNil() bool {
 	return len(g.errors) == 0
 }
+
+// Unwrap returns the errors, so errors.Is and errors.As match each of them.
+func (g *Group) Unwrap() []error {
+	return g.errors
+}
@@ -57,3 +57,11 @@ func TestGroup_IsNil(t *testing.T) {
 	assert.Equal(t, g, g.Add(err1))
 	assert.False(t, g.IsNil())
 }
+
+func TestGroup_Unwrap(t *testing.T) {
+	g := NewGroup().Add(err1, err2)
+
+	assert.True(t, errors.Is(g, err1))
+	assert.True(t, errors.Is(g, err2))
+	assert.False(t, errors.Is(g, err3))
+}
@@ -1,9 +1,19 @@
 package event
 
 import (
+	"context"
+	"errors"
+	"reflect"
 	"sync"
+
+	kerrors "github.com/go-kratos-ecosystem/components/v2/errors"
+	"github.com/go-kratos-ecosystem/components/v2/features"
 )
 
+// ErrStopPropagation is returned by the context listeners to stop the propagation of the event,
+// the listeners with the lower priorities are skipped. It is not reported as an error.
+var ErrStopPropagation = errors.New("event: stop propagation")
+
 type Event interface {
 	Event() any
 }
@@ -13,15 +23,61 @@ type Listener interface {
 	Handle(event Event)
 }
 
+// ContextListener is the listener receiving the context, and failing the dispatch with the error.
+//
+// The listeners run in the order of the priorities, if they implement features.Prioritized,
+// the higher priorities run first, the default is 0.
+type ContextListener interface {
+	Listen() []Event
+	Handle(ctx context.Context, event Event) error
+}
+
+// adapter adapts the Listener to the ContextListener.
+type adapter struct {
+	Listener
+}
+
+func (a *adapter) Handle(_ context.Context, event Event) error {
+	a.Listener.Handle(event)
+	return nil
+}
+
+type registration struct {
+	listener ContextListener
+	origin   Listener // the adapted listener, nil for the context listeners
+	priority int
+	name     string
+	queued   bool
+	seq      uint64 // the order of the addition
+}
+
+// key returns the listener added by the user, for the removal.
+func (r registration) key() any {
+	if r.origin != nil {
+		return r.origin
+	}
+	return r.listener
+}
+
 type Dispatcher struct {
-	listeners map[any][]Listener
-	mu        sync.RWMutex
-	recovery  func(err any, listener Listener, event Event)
-	waiter    sync.WaitGroup
+	listeners  map[any][]registration
+	patterns   map[Pattern][]registration
+	interfaces map[reflect.Type][]registration
+	cache      map[reflect.Type]cacheEntry // the matched registrations of the typed events by the type
+	seq        uint64
+	mu         sync.RWMutex
+
+	recovery func(err any, listener Listener, event Event)
+	waiter   sync.WaitGroup
+
+	queue  Queue
+	queued map[string]registration // the queued listeners by the name
+	events map[string]reflect.Type // the events by the name
 }
 
 type Option func(*Dispatcher)
 
+// WithRecovery recovers the panics of the listeners, the listener is nil for the context listeners.
 func WithRecovery(recovery func(err any, listener Listener, event Event)) Option {
 	return func(d *Dispatcher) {
 		d.recovery = recovery
@@ -30,7 +86,12 @@ func WithRecovery(recovery func(err any, listener Listener, event Event)) Option
 
 func NewDispatcher(opts ...Option) *Dispatcher {
 	d := &Dispatcher{
-		listeners: make(map[any][]Listener),
+		listeners:  make(map[any][]registration),
+		patterns:   make(map[Pattern][]registration),
+		interfaces: make(map[reflect.Type][]registration),
+		cache:      make(map[reflect.Type]cacheEntry),
+		queued:     make(map[string]registration),
+		events:     make(map[string]reflect.Type),
 	}
 
 	for _, opt := range opts {
@@ -40,6 +101,7 @@ func NewDispatcher(opts ...Option) *Dispatcher {
 	return d
 }
 
+// AddListener adds the listeners, which are adapted to the context listeners.
 func (d *Dispatcher) AddListener(listeners ...Listener) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -48,48 +110,261 @@ func (d *Dispatcher) AddListener(listeners ...Listener) {
 		if listener == nil {
 			continue
 		}
-		for _, event := range listener.Listen() {
-			e := event.Event()
-			if _, ok := d.listeners[e]; !ok {
-				d.listeners[e] = make([]Listener, 0)
+		d.add(registration{
+			listener: &adapter{Listener: listener},
+			origin:   listener,
+			priority: priority(listener),
+		})
+	}
+}
+
+// AddContextListener adds the context listeners.
+func (d *Dispatcher) AddContextListener(listeners ...ContextListener) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, listener := range listeners {
+		if listener == nil {
+			continue
+		}
+		d.add(registration{
+			listener: listener,
+			priority: priority(listener),
+		})
+	}
+}
+
+func (d *Dispatcher) add(r registration) {
+	r.name = ListenerName(r.key())
+	r.queued = queued(r.key())
+	if r.queued {
+		d.queued[r.name] = r
+	}
+
+	d.seq++
+	r.seq = d.seq
+
+	for _, event := range r.listener.Listen() {
+		switch key := event.Event().(type) {
+		case Pattern:
+			if key.wildcard() {
+				d.patterns[key] = append(d.patterns[key], r)
+			} else {
+				d.listeners[string(key)] = append(d.listeners[string(key)], r)
+			}
+		case implementsKey:
+			d.interfaces[key.typ] = append(d.interfaces[key.typ], r)
+		default:
+			d.events[EventName(event)] = reflect.TypeOf(event)
+			d.listeners[key] = append(d.listeners[key], r)
+		}
+	}
+
+	clear(d.cache)
+}
+
+// RemoveListener removes the listeners from all the events.
+func (d *Dispatcher) RemoveListener(listeners ...Listener) {
+	keys := make([]any, 0, len(listeners))
+	for _, listener := range listeners {
+		keys = append(keys, listener)
+	}
+	d.remove(keys)
+}
+
+// RemoveContextListener removes the context listeners from all the events.
+func (d *Dispatcher) RemoveContextListener(listeners ...ContextListener) {
+	keys := make([]any, 0, len(listeners))
+	for _, listener := range listeners {
+		keys = append(keys, listener)
+	}
+	d.remove(keys)
+}
+
+func (d *Dispatcher) remove(keys []any) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for name, r := range d.queued {
+		if contains(keys, r.key()) {
+			delete(d.queued, name)
+		}
+	}
+
+	removeFrom(d.listeners, keys)
+	removeFrom(d.patterns, keys)
+	removeFrom(d.interfaces, keys)
+
+	clear(d.cache)
+}
+
+func removeFrom[K comparable](listeners map[K][]registration, keys []any) {
+	for e, registrations := range listeners {
+		kept := make([]registration, 0, len(registrations))
+		for _, r := range registrations {
+			if !contains(keys, r.key()) {
+				kept = append(kept, r)
 			}
-			d.listeners[e] = append(d.listeners[e], listener)
 		}
+
+		if len(kept) == 0 {
+			delete(listeners, e)
+		} else {
+			listeners[e] = kept
+		}
+	}
+}
+
+// RegisterEvent registers the types of the events for decoding the queued events,
+// which is required by the consumers for the events listened by Pattern or Implements only,
+// since they are not known until dispatched.
+func (d *Dispatcher) RegisterEvent(events ...Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, event := range events {
+		if event != nil {
+			d.events[EventName(event)] = reflect.TypeOf(event)
+		}
+	}
+}
+
+// registrations returns the registrations matching the event, the returned slice must not be modified.
+//
+// The registrations of the typed events are cached by the type, while the string-named events are not cached,
+// since their names may be dynamic and the cache would grow without bound.
+func (d *Dispatcher) registrations(event Event) []registration {
+	typ, key := reflect.TypeOf(event), event.Event()
+
+	if _, ok := key.(string); ok {
+		d.mu.RLock()
+		registrations := d.match(typ, key)
+		_, known := d.events[EventName(event)]
+		d.mu.RUnlock()
+
+		// for decoding the queued events of the wildcard listeners
+		if !known && hasQueued(registrations) {
+			d.RegisterEvent(event)
+		}
+		return registrations
+	}
+
+	d.mu.RLock()
+	entry, ok := d.cache[typ]
+	d.mu.RUnlock()
+	if ok && entry.event == key {
+		return entry.registrations
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if entry, ok = d.cache[typ]; !ok || entry.event != key {
+		entry = cacheEntry{event: key, registrations: d.match(typ, key)}
+		d.cache[typ] = entry
+
+		// for decoding the queued events of the wildcard and interface listeners
+		d.events[EventName(event)] = typ
 	}
+	return entry.registrations
 }
 
+// Dispatch dispatches the event with the background context, the errors of the listeners are discarded.
 func (d *Dispatcher) Dispatch(event Event) {
-	if listeners, ok := d.listeners[event.Event()]; ok {
-		for _, listener := range listeners {
-			d.waiter.Add(1)
-			d.handle(listener, event)
+	_ = d.DispatchContext(context.Background(), event)
+}
+
+// DispatchContext runs the listeners of the event in the order of the priorities,
+// until a listener returns ErrStopPropagation. The errors of the listeners are returned as a *errors.Group.
+//
+// The queued listeners are enqueued if the queue is set, the errors of the queue are returned too.
+func (d *Dispatcher) DispatchContext(ctx context.Context, event Event) error {
+	group := kerrors.NewGroup()
+
+	for _, r := range d.registrations(event) {
+		if r.queued && d.queue != nil {
+			group.Add(d.enqueue(ctx, r, event))
+			continue
 		}
+
+		d.waiter.Add(1)
+		err := d.handle(ctx, r, event)
+
+		if errors.Is(err, ErrStopPropagation) {
+			if err != ErrStopPropagation { //nolint:errorlint
+				group.Add(err)
+			}
+			break
+		}
+		group.Add(err)
+	}
+
+	if group.IsNil() {
+		return nil
 	}
+	return group
 }
 
+// DispatchAsync dispatches the event with the background context asynchronously.
 func (d *Dispatcher) DispatchAsync(event Event) {
-	if listeners, ok := d.listeners[event.Event()]; ok {
-		for _, listener := range listeners {
-			d.waiter.Add(1)
-			go d.handle(listener, event)
+	d.DispatchAsyncContext(context.Background(), event)
+}
+
+// DispatchAsyncContext runs the listeners of the event concurrently, with the values of ctx but not its cancellation.
+// The errors of the listeners are discarded, and the propagation can not be stopped.
+func (d *Dispatcher) DispatchAsyncContext(ctx context.Context, event Event) {
+	ctx = context.WithoutCancel(ctx)
+
+	for _, r := range d.registrations(event) {
+		if r.queued && d.queue != nil {
+			_ = d.enqueue(ctx, r, event)
+			continue
 		}
+
+		d.waiter.Add(1)
+		go d.handle(ctx, r, event) //nolint:errcheck
 	}
 }
 
-func (d *Dispatcher) handle(listener Listener, event Event) {
+func (d *Dispatcher) enqueue(ctx context.Context, r registration, event Event) error {
+	return d.queue.Enqueue(ctx, &Job{Listener: r.name, Event: event})
+}
+
+func (d *Dispatcher) handle(ctx context.Context, r registration, event Event) error {
 	defer d.waiter.Done()
 
 	if d.recovery != nil {
 		defer func() {
 			if err := recover(); err != nil {
-				d.recovery(err, listener, event)
+				d.recovery(err, r.origin, event)
 			}
 		}()
 	}
 
-	listener.Handle(event)
+	return r.listener.Handle(ctx, event)
 }
 
 func (d *Dispatcher) Wait() {
 	d.waiter.Wait()
 }
+
+func priority(listener any) int {
+	if p, ok := listener.(features.Prioritized); ok {
+		return p.Priority()
+	}
+	return 0
+}
+
+func contains(keys []any, key any) bool {
+	// the values of the uncomparable types never equal, instead of panicking
+	if !reflect.TypeOf(key).Comparable() {
+		return false
+	}
+
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
@@ -1,9 +1,16 @@
 package event
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	kerrors "github.com/go-kratos-ecosystem/components/v2/errors"
+	"github.com/go-kratos-ecosystem/components/v2/features"
 )
 
 var ch = make(chan string, 1)
@@ -69,3 +76,142 @@ func TestDispatcher(t *testing.T) {
 	d.Wait()
 	assert.Equal(t, "345", <-ch)
 }
+
+type orderEvent struct {
+	ID int
+}
+
+func (e *orderEvent) Event() any {
+	return orderEvent{}
+}
+
+type ctxKey struct{}
+
+type orderListener struct {
+	*features.PriorityFeature
+	name   string
+	err    error
+	called *[]string
+	mu     *sync.Mutex
+}
+
+func (l *orderListener) Listen() []Event {
+	return []Event{&orderEvent{}}
+}
+
+func (l *orderListener) Handle(ctx context.Context, _ Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	*l.called = append(*l.called, l.name+":"+fmt.Sprint(ctx.Value(ctxKey{})))
+	return l.err
+}
+
+type legacyOrderListener struct {
+	called *[]string
+	mu     *sync.Mutex
+}
+
+func (l *legacyOrderListener) Listen() []Event {
+	return []Event{&orderEvent{}}
+}
+
+func (l *legacyOrderListener) Handle(Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	*l.called = append(*l.called, "legacy")
+}
+
+func TestDispatcher_Context(t *testing.T) {
+	var (
+		called []string
+		mu     sync.Mutex
+		err1   = errors.New("err1")
+		err2   = errors.New("err2")
+	)
+
+	newListener := func(name string, priority int, err error) *orderListener {
+		return &orderListener{
+			PriorityFeature: features.NewPriorityFeature(priority),
+			name:            name,
+			err:             err,
+			called:          &called,
+			mu:              &mu,
+		}
+	}
+
+	var (
+		low    = newListener("low", -1, nil)
+		high   = newListener("high", 10, err1)
+		normal = newListener("normal", 0, err2)
+		legacy = &legacyOrderListener{called: &called, mu: &mu}
+	)
+
+	d := NewDispatcher()
+	d.AddContextListener(low, normal)
+	d.AddListener(legacy)
+	d.AddContextListener(high)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "v")
+
+	// the priorities, and the order of the addition with the same priority
+	err := d.DispatchContext(ctx, &orderEvent{ID: 1})
+	assert.Equal(t, []string{"high:v", "normal:v", "legacy", "low:v"}, called)
+	assert.ErrorIs(t, err, err1)
+	assert.ErrorIs(t, err, err2)
+
+	var group *kerrors.Group
+	assert.ErrorAs(t, err, &group)
+	assert.Equal(t, []error{err1, err2}, group.Errors())
+
+	// stops the propagation
+	called = nil
+	normal.err = ErrStopPropagation
+	err = d.DispatchContext(ctx, &orderEvent{ID: 2})
+	assert.Equal(t, []string{"high:v", "normal:v"}, called)
+	assert.Equal(t, []error{err1}, err.(*kerrors.Group).Errors())
+
+	// removes the listeners
+	called = nil
+	d.RemoveContextListener(high, normal)
+	d.RemoveListener(legacy)
+	assert.NoError(t, d.DispatchContext(ctx, &orderEvent{ID: 3}))
+	assert.Equal(t, []string{"low:v"}, called)
+
+	d.RemoveContextListener(low)
+	assert.NoError(t, d.DispatchContext(ctx, &orderEvent{ID: 4}))
+	assert.Equal(t, []string{"low:v"}, called)
+
+	// asynchronously, the values of the context are kept after the cancellation
+	called = nil
+	d.AddContextListener(low, high)
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	d.DispatchAsyncContext(cctx, &orderEvent{ID: 5})
+	d.Wait()
+	assert.ElementsMatch(t, []string{"high:v", "low:v"}, called)
+}
+
+type panicListener struct{}
+
+func (l *panicListener) Listen() []Event {
+	return []Event{&orderEvent{}}
+}
+
+func (l *panicListener) Handle(context.Context, Event) error {
+	panic("panic")
+}
+
+func TestDispatcher_ContextRecovery(t *testing.T) {
+	var recovered any
+
+	d := NewDispatcher(WithRecovery(func(err any, listener Listener, _ Event) {
+		recovered = err
+		assert.Nil(t, listener)
+	}))
+	d.AddContextListener(&panicListener{})
+
+	assert.NoError(t, d.DispatchContext(context.Background(), &orderEvent{}))
+	assert.Equal(t, "panic", recovered)
+}
@@ -0,0 +1,126 @@
+package event
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Pattern is listened for the string-named events, whose Event returns the name, by the wildcard pattern.
+// The '*' matches any characters, so "order.*" matches "order.created" and "order.item.added",
+// and "*" matches all the events, including the ones not named.
+//
+// Example:
+//
+//	func (l *auditListener) Listen() []event.Event {
+//	  return []event.Event{event.Pattern("*")}
+//	}
+type Pattern string
+
+func (p Pattern) Event() any {
+	return p
+}
+
+func (p Pattern) wildcard() bool {
+	return strings.Contains(string(p), "*")
+}
+
+// Match reports whether the name matches the pattern.
+func (p Pattern) Match(name string) bool {
+	pattern := string(p)
+
+	// the last star and the position of the name it matches, for backtracking
+	i, j, star, next := 0, 0, -1, 0
+
+	for j < len(name) {
+		switch {
+		case i < len(pattern) && pattern[i] == '*':
+			star, next = i, j
+			i++
+		case i < len(pattern) && pattern[i] == name[j]:
+			i++
+			j++
+		case star >= 0:
+			next++
+			i, j = star+1, next
+		default:
+			return false
+		}
+	}
+
+	return strings.Trim(pattern[i:], "*") == ""
+}
+
+type implementsKey struct {
+	typ reflect.Type
+}
+
+type implements struct {
+	key implementsKey
+}
+
+func (i implements) Event() any {
+	return i.key
+}
+
+// Implements is listened for the events assignable to T, usually an interface.
+//
+// Example:
+//
+//	func (l *orderListener) Listen() []event.Event {
+//	  return []event.Event{event.Implements[OrderEvent]()}
+//	}
+func Implements[T any]() Event {
+	return implements{key: implementsKey{typ: reflect.TypeFor[T]()}}
+}
+
+// cacheEntry is the cached registrations of the type, which are matched by the event key.
+// The key is checked on the lookup, so the cache never outgrows the types of the events.
+type cacheEntry struct {
+	event         any
+	registrations []registration
+}
+
+// match returns the registrations matching the event in the order of the priorities and the addition,
+// the registration matching the event in many ways runs once.
+func (d *Dispatcher) match(typ reflect.Type, key any) []registration {
+	var (
+		registrations []registration
+		seen          = make(map[uint64]struct{})
+	)
+	add := func(rs []registration) {
+		for _, r := range rs {
+			if _, ok := seen[r.seq]; !ok {
+				seen[r.seq] = struct{}{}
+				registrations = append(registrations, r)
+			}
+		}
+	}
+
+	add(d.listeners[key])
+
+	if name, ok := key.(string); ok {
+		for pattern, rs := range d.patterns {
+			if pattern.Match(name) {
+				add(rs)
+			}
+		}
+	} else {
+		add(d.patterns["*"])
+	}
+
+	for t, rs := range d.interfaces {
+		if typ.AssignableTo(t) {
+			add(rs)
+		}
+	}
+
+	sort.SliceStable(registrations, func(i, j int) bool {
+		if registrations[i].priority != registrations[j].priority {
+			return registrations[i].priority > registrations[j].priority
+		}
+		return registrations[i].seq < registrations[j].seq
+	})
+
+	return registrations
+}
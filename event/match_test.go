@@ -0,0 +1,179 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/features"
+)
+
+func TestPattern_Match(t *testing.T) {
+	tests := []struct {
+		pattern Pattern
+		name    string
+		want    bool
+	}{
+		{"*", "", true},
+		{"*", "order.created", true},
+		{"order.*", "order.created", true},
+		{"order.*", "order.item.added", true},
+		{"order.*", "order.", true},
+		{"order.*", "order", false},
+		{"order.*", "user.created", false},
+		{"*.created", "order.created", true},
+		{"*.created", "order.updated", false},
+		{"order.*.added", "order.item.added", true},
+		{"order.*.added", "order.item.removed", false},
+		{"o*r*x", "order.created", false},
+		{"o*r*d", "order.created.d", true},
+		{"order.created", "order.created", true},
+		{"", "", true},
+		{"", "order", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tt.pattern.Match(tt.name), "%s %s", tt.pattern, tt.name)
+	}
+}
+
+type namedEvent string
+
+func (e namedEvent) Event() any {
+	return string(e)
+}
+
+type orderNotification interface {
+	Event
+	Order() int
+}
+
+func (e *orderEvent) Order() int {
+	return e.ID
+}
+
+type recordListener struct {
+	*features.PriorityFeature
+	name   string
+	events []Event
+	record *[]string
+}
+
+func (l *recordListener) Listen() []Event {
+	return l.events
+}
+
+func (l *recordListener) Handle(_ context.Context, event Event) error {
+	*l.record = append(*l.record, l.name+":"+fmt.Sprintf("%T", event))
+	return nil
+}
+
+func TestDispatcher_Match(t *testing.T) {
+	var record []string
+
+	newListener := func(name string, priority int, events ...Event) *recordListener {
+		return &recordListener{
+			PriorityFeature: features.NewPriorityFeature(priority),
+			name:            name,
+			events:          events,
+			record:          &record,
+		}
+	}
+
+	var (
+		audit   = newListener("audit", -1, Pattern("*"))
+		orders  = newListener("orders", 0, Pattern("order.*"), namedEvent("order.created"))
+		created = newListener("created", 0, namedEvent("order.created"), Pattern("order.created"))
+		typed   = newListener("typed", 1, Implements[orderNotification]())
+	)
+
+	d := NewDispatcher()
+	d.AddContextListener(audit, orders, created, typed)
+
+	// the wildcards, and the listener matching the event in many ways runs once
+	assert.NoError(t, d.DispatchContext(context.Background(), namedEvent("order.created")))
+	assert.Equal(t, []string{
+		"orders:event.namedEvent",
+		"created:event.namedEvent",
+		"audit:event.namedEvent",
+	}, record)
+
+	record = nil
+	assert.NoError(t, d.DispatchContext(context.Background(), namedEvent("user.created")))
+	assert.Equal(t, []string{"audit:event.namedEvent"}, record)
+
+	// the interfaces, and the wildcard for all the events
+	record = nil
+	assert.NoError(t, d.DispatchContext(context.Background(), &orderEvent{ID: 1}))
+	assert.Equal(t, []string{"typed:*event.orderEvent", "audit:*event.orderEvent"}, record)
+
+	record = nil
+	assert.NoError(t, d.DispatchContext(context.Background(), &testEvent{}))
+	assert.Equal(t, []string{"audit:*event.testEvent"}, record)
+
+	// the typed events are cached by the types, and the cache is reset on the changes
+	assert.Len(t, d.cache, 2)
+
+	d.RemoveContextListener(audit, typed)
+	assert.Empty(t, d.cache)
+
+	record = nil
+	assert.NoError(t, d.DispatchContext(context.Background(), &orderEvent{ID: 2}))
+	assert.Empty(t, record)
+
+	d.AddContextListener(typed)
+	assert.NoError(t, d.DispatchContext(context.Background(), &orderEvent{ID: 3}))
+	assert.Equal(t, []string{"typed:*event.orderEvent"}, record)
+}
+
+type codeEvent int
+
+func (e codeEvent) Event() any {
+	return int(e)
+}
+
+func TestDispatcher_MatchCache(t *testing.T) {
+	var record []string
+
+	d := NewDispatcher()
+	d.AddContextListener(&recordListener{
+		PriorityFeature: features.NewPriorityFeature(0),
+		name:            "code",
+		events:          []Event{codeEvent(1)},
+		record:          &record,
+	})
+
+	// cached by the type, and checked by the event
+	for _, code := range []codeEvent{1, 2, 1} {
+		assert.NoError(t, d.DispatchContext(context.Background(), code))
+	}
+	assert.Equal(t, []string{"code:event.codeEvent", "code:event.codeEvent"}, record)
+	assert.Len(t, d.cache, 1)
+
+	// the dynamic names are not cached
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, d.DispatchContext(context.Background(), namedEvent(fmt.Sprintf("order.%d", i))))
+	}
+	assert.Len(t, d.cache, 1)
+}
+
+func BenchmarkDispatcher_Match(b *testing.B) {
+	var record []string
+
+	d := NewDispatcher()
+	for _, pattern := range []Pattern{"*", "order.*", "user.*", "*.created"} {
+		d.AddContextListener(&recordListener{
+			PriorityFeature: features.NewPriorityFeature(0),
+			events:          []Event{pattern},
+			record:          &record,
+		})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = d.DispatchContext(context.Background(), namedEvent("order.created"))
+		record = record[:0]
+	}
+}
@@ -0,0 +1,113 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-kratos-ecosystem/components/v2/features"
+)
+
+var ErrListenerNotFound = errors.New("event: the queued listener is not found")
+
+// Queue is the durable queue of the listeners implementing features.Queueable,
+// the jobs are processed by Dispatcher.Process of the consumers.
+type Queue interface {
+	Enqueue(ctx context.Context, job *Job) error
+}
+
+// Job is the event queued for the listener.
+type Job struct {
+	Listener string
+	Event    Event
+}
+
+// WithQueue enqueues the events for the queued listeners, instead of running them.
+func WithQueue(queue Queue) Option {
+	return func(d *Dispatcher) {
+		d.queue = queue
+	}
+}
+
+// ListenerName returns the name of the listener, which is features.Named or the type of the listener.
+func ListenerName(listener any) string {
+	if named, ok := listener.(features.Named); ok {
+		return named.Name()
+	}
+	return typeName(reflect.TypeOf(listener))
+}
+
+// EventName returns the name of the event, which is the type of the event.
+func EventName(event Event) string {
+	return typeName(reflect.TypeOf(event))
+}
+
+// typeName returns the name of the type qualified by the full package path, e.g. "*github.com/foo/bar.Event",
+// so the types of the same name in the different packages do not collide.
+func typeName(typ reflect.Type) string {
+	var pointers string
+	for typ.Kind() == reflect.Pointer {
+		pointers += "*"
+		typ = typ.Elem()
+	}
+
+	if typ.Name() == "" || typ.PkgPath() == "" {
+		return pointers + typ.String()
+	}
+	return pointers + typ.PkgPath() + "." + typ.Name()
+}
+
+func queued(listener any) bool {
+	q, ok := listener.(features.Queueable)
+	return ok && q.Queued()
+}
+
+func hasQueued(registrations []registration) bool {
+	for _, r := range registrations {
+		if r.queued {
+			return true
+		}
+	}
+	return false
+}
+
+// QueuedListener returns the queued listener by the name.
+func (d *Dispatcher) QueuedListener(name string) (any, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	r, ok := d.queued[name]
+	if !ok {
+		return nil, false
+	}
+	return r.key(), true
+}
+
+// EventType returns the type of the event listened by the listeners, by the name of the event.
+func (d *Dispatcher) EventType(name string) (reflect.Type, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	typ, ok := d.events[name]
+	return typ, ok
+}
+
+// Process runs the queued listener of the job, the panic is returned as an error.
+func (d *Dispatcher) Process(ctx context.Context, job *Job) (err error) {
+	d.mu.RLock()
+	r, ok := d.queued[job.Listener]
+	d.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrListenerNotFound, job.Listener)
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("event: the listener %s panics: %v", job.Listener, v)
+		}
+	}()
+
+	return r.listener.Handle(ctx, job.Event)
+}
@@ -0,0 +1,139 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/features"
+)
+
+type memoryQueue struct {
+	jobs []*Job
+}
+
+func (q *memoryQueue) Enqueue(_ context.Context, job *Job) error {
+	q.jobs = append(q.jobs, job)
+	return nil
+}
+
+type queuedListener struct {
+	features.QueueFeature
+	events []Event
+}
+
+func (l *queuedListener) Listen() []Event {
+	return []Event{&orderEvent{}}
+}
+
+func (l *queuedListener) Handle(event Event) {
+	if event.(*orderEvent).ID < 0 {
+		panic("invalid id")
+	}
+	l.events = append(l.events, event)
+}
+
+const queuedListenerName = "*github.com/go-kratos-ecosystem/components/v2/event.queuedListener"
+
+func TestDispatcher_Queue(t *testing.T) {
+	var (
+		q = &memoryQueue{}
+		l = &queuedListener{}
+		d = NewDispatcher(WithQueue(q))
+	)
+	d.AddListener(l)
+
+	// enqueued
+	assert.NoError(t, d.DispatchContext(context.Background(), &orderEvent{ID: 1}))
+	assert.Empty(t, l.events)
+	assert.Equal(t, []*Job{{Listener: queuedListenerName, Event: &orderEvent{ID: 1}}}, q.jobs)
+
+	listener, ok := d.QueuedListener(queuedListenerName)
+	assert.True(t, ok)
+	assert.Equal(t, l, listener)
+
+	typ, ok := d.EventType("*github.com/go-kratos-ecosystem/components/v2/event.orderEvent")
+	assert.True(t, ok)
+	assert.Equal(t, reflect.TypeOf(&orderEvent{}), typ)
+
+	// processed
+	assert.NoError(t, d.Process(context.Background(), q.jobs[0]))
+	assert.Equal(t, []Event{&orderEvent{ID: 1}}, l.events)
+
+	assert.ErrorContains(t, d.Process(context.Background(), &Job{
+		Listener: queuedListenerName,
+		Event:    &orderEvent{ID: -1},
+	}), "invalid id")
+	assert.True(t, errors.Is(d.Process(context.Background(), &Job{Listener: "unknown"}), ErrListenerNotFound))
+
+	// removed
+	d.RemoveListener(l)
+	_, ok = d.QueuedListener(queuedListenerName)
+	assert.False(t, ok)
+
+	// runs right away without the queue
+	d = NewDispatcher()
+	d.AddListener(l)
+	d.Dispatch(&orderEvent{ID: 2})
+	assert.Equal(t, []Event{&orderEvent{ID: 1}, &orderEvent{ID: 2}}, l.events)
+}
+
+type patternListener struct {
+	features.QueueFeature
+}
+
+func (l *patternListener) Listen() []Event {
+	return []Event{Pattern("order.*")}
+}
+
+func (l *patternListener) Handle(Event) {}
+
+func TestDispatcher_RegisterEvent(t *testing.T) {
+	name := EventName(namedEvent(""))
+
+	// the consumer knows the events of the wildcard listeners by the registration
+	d := NewDispatcher()
+	d.AddListener(&patternListener{})
+	_, ok := d.EventType(name)
+	assert.False(t, ok)
+
+	d.RegisterEvent(namedEvent(""), nil)
+	typ, ok := d.EventType(name)
+	assert.True(t, ok)
+	assert.Equal(t, reflect.TypeOf(namedEvent("")), typ)
+
+	// the producer records the events on the dispatching
+	d = NewDispatcher(WithQueue(&memoryQueue{}))
+	d.AddListener(&patternListener{})
+	assert.NoError(t, d.DispatchContext(context.Background(), namedEvent("user.created")))
+	_, ok = d.EventType(name)
+	assert.False(t, ok)
+
+	assert.NoError(t, d.DispatchContext(context.Background(), namedEvent("order.created")))
+	_, ok = d.EventType(name)
+	assert.True(t, ok)
+}
+
+type namedListener struct {
+	*features.NamedFeature
+	queuedListener
+}
+
+func TestNames(t *testing.T) {
+	// qualified by the package path
+	assert.Equal(t, "*github.com/go-kratos-ecosystem/components/v2/event.orderEvent", EventName(&orderEvent{}))
+	assert.Equal(t, "github.com/go-kratos-ecosystem/components/v2/event.namedEvent", EventName(namedEvent("")))
+	assert.Equal(t, queuedListenerName, ListenerName(&queuedListener{}))
+	assert.Equal(t, "*github.com/go-kratos-ecosystem/components/v2/features.QueueFeature",
+		ListenerName(&features.QueueFeature{}))
+
+	// the unnamed types
+	assert.Equal(t, "[]string", typeName(reflect.TypeOf([]string{})))
+	assert.Equal(t, "*int", typeName(reflect.TypeOf(new(int))))
+
+	// features.Named first
+	assert.Equal(t, "named", ListenerName(&namedListener{NamedFeature: features.NewNamedFeature("named")}))
+}
@@ -0,0 +1,133 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos-ecosystem/components/v2/codec"
+	"github.com/go-kratos-ecosystem/components/v2/codec/json"
+	"github.com/go-kratos-ecosystem/components/v2/event"
+)
+
+// the fields of the messages in the stream
+const (
+	fieldListener = "listener"
+	fieldEvent    = "event"
+	fieldPayload  = "payload"
+	fieldAttempts = "attempts"
+	fieldError    = "error"
+)
+
+// Queue enqueues the events of the queued listeners onto the Redis Stream.
+type Queue struct {
+	redis  redis.UniversalClient
+	stream string
+	codec  codec.Codec
+	maxLen int64
+}
+
+type Option func(*Queue)
+
+// WithStream sets the name of the stream, the default is "events".
+func WithStream(stream string) Option {
+	return func(q *Queue) {
+		q.stream = stream
+	}
+}
+
+// WithCodec sets the codec of the events, the default is json.
+func WithCodec(codec codec.Codec) Option {
+	return func(q *Queue) {
+		q.codec = codec
+	}
+}
+
+// WithMaxLen trims the stream to approximately maxLen messages, the default is 0, which means no trimming.
+func WithMaxLen(maxLen int64) Option {
+	return func(q *Queue) {
+		q.maxLen = maxLen
+	}
+}
+
+var _ event.Queue = (*Queue)(nil)
+
+func NewQueue(redis redis.UniversalClient, opts ...Option) *Queue {
+	q := &Queue{
+		redis:  redis,
+		stream: "events",
+		codec:  json.Codec,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Stream returns the name of the stream.
+func (q *Queue) Stream() string {
+	return q.stream
+}
+
+func (q *Queue) Enqueue(ctx context.Context, job *event.Job) error {
+	payload, err := q.codec.Marshal(job.Event)
+	if err != nil {
+		return err
+	}
+
+	return q.redis.XAdd(ctx, q.args(q.stream, map[string]any{
+		fieldListener: job.Listener,
+		fieldEvent:    event.EventName(job.Event),
+		fieldPayload:  payload,
+		fieldAttempts: 0,
+	})).Err()
+}
+
+func (q *Queue) args(stream string, values map[string]any) *redis.XAddArgs {
+	return &redis.XAddArgs{
+		Stream: stream,
+		MaxLen: q.maxLen,
+		Approx: q.maxLen > 0,
+		Values: values,
+	}
+}
+
+// message is the decoded message of the stream.
+type message struct {
+	id       string
+	listener string
+	event    string
+	payload  string
+	attempts int
+}
+
+func parse(msg redis.XMessage) message {
+	m := message{id: msg.ID}
+	m.listener, _ = msg.Values[fieldListener].(string)
+	m.event, _ = msg.Values[fieldEvent].(string)
+	m.payload, _ = msg.Values[fieldPayload].(string)
+	if attempts, ok := msg.Values[fieldAttempts].(string); ok {
+		m.attempts, _ = strconv.Atoi(attempts)
+	}
+	return m
+}
+
+// fields returns the flat fields of the message, for adding it back to the stream by the scripts.
+func (m message) fields() []string {
+	return []string{
+		fieldListener, m.listener,
+		fieldEvent, m.event,
+		fieldPayload, m.payload,
+		fieldAttempts, strconv.Itoa(m.attempts),
+	}
+}
+
+func (m message) values() map[string]any {
+	return map[string]any{
+		fieldListener: m.listener,
+		fieldEvent:    m.event,
+		fieldPayload:  m.payload,
+		fieldAttempts: m.attempts,
+	}
+}
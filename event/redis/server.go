@@ -0,0 +1,382 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos-ecosystem/components/v2/event"
+	"github.com/go-kratos-ecosystem/components/v2/features"
+)
+
+var ErrUnknownEvent = errors.New("event/redis: the event is unknown")
+
+// nowScript is the Lua snippet to get the current time of redis in milliseconds.
+const nowScript = `local t = redis.call("time")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+`
+
+// retryScript is a Lua script to delay the retry of the event and acknowledge the original one in an atomic way.
+//
+//	KEYS[1] is the delayed set
+//	KEYS[2] is the stream
+//	ARGV[1] is the group
+//	ARGV[2] is the id of the original event
+//	ARGV[3] is the delay in milliseconds
+//	ARGV[4] is the delayed event
+const retryScript = nowScript + `redis.call("zadd",KEYS[1],now+tonumber(ARGV[3]),ARGV[4])
+return redis.call("xack",KEYS[2],ARGV[1],ARGV[2])`
+
+// promoteScript is a Lua script to move the due events from the delayed set back to the stream in an atomic way.
+//
+//	KEYS[1] is the delayed set
+//	KEYS[2] is the stream
+//	ARGV[1] is the maximum number of the events moved
+//	ARGV[2] is the approximate max length of the stream, 0 means no trimming
+const promoteScript = nowScript + `local members = redis.call("zrangebyscore",KEYS[1],"-inf",now,"limit",0,tonumber(ARGV[1]))
+for _, member in ipairs(members) do
+    local fields = cjson.decode(member).fields
+    if tonumber(ARGV[2]) > 0 then
+        redis.call("xadd",KEYS[2],"maxlen","~",ARGV[2],"*",unpack(fields))
+    else
+        redis.call("xadd",KEYS[2],"*",unpack(fields))
+    end
+    redis.call("zrem",KEYS[1],member)
+end
+return #members`
+
+// delayedMessage is the member of the delayed set, the id keeps the members of the same fields apart.
+type delayedMessage struct {
+	ID     string   `json:"id"`
+	Fields []string `json:"fields"`
+}
+
+// Server processes the queued events of the stream with the consumer group,
+// the failed events are retried with the backoff up to features.Retriable of the listener,
+// and then moved to the dead letter stream.
+//
+// The failed events are acknowledged right away, and kept in the sorted set "{stream}:delayed" until they are due,
+// so the other events are not held up, and the retries are not claimed by the other consumers.
+type Server struct {
+	queue      *Queue
+	dispatcher *event.Dispatcher
+
+	group       string
+	consumer    string
+	concurrency int
+	block       time.Duration
+	claimIdle   time.Duration
+	deadLetter  string
+	retryDelay  time.Duration
+	delayed     string
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+type ServerOption func(*Server)
+
+// WithGroup sets the name of the consumer group, the default is "events".
+func WithGroup(group string) ServerOption {
+	return func(s *Server) {
+		s.group = group
+	}
+}
+
+// WithConsumer sets the name of the consumer, which should be unique in the group. The default is a uuid.
+func WithConsumer(consumer string) ServerOption {
+	return func(s *Server) {
+		s.consumer = consumer
+	}
+}
+
+// WithConcurrency sets the number of the events processed at the same time, the default is 10.
+func WithConcurrency(concurrency int) ServerOption {
+	return func(s *Server) {
+		s.concurrency = concurrency
+	}
+}
+
+// WithBlock sets the duration of blocking for the new events, the default is 1 second.
+func WithBlock(block time.Duration) ServerOption {
+	return func(s *Server) {
+		s.block = block
+	}
+}
+
+// WithClaimIdle sets the idle duration after which the pending events of the other consumers,
+// which may be crashed, are claimed. The default is 5 minutes.
+func WithClaimIdle(idle time.Duration) ServerOption {
+	return func(s *Server) {
+		s.claimIdle = idle
+	}
+}
+
+// WithDeadLetter sets the name of the dead letter stream, the default is the stream with the suffix ":dead".
+func WithDeadLetter(stream string) ServerOption {
+	return func(s *Server) {
+		s.deadLetter = stream
+	}
+}
+
+// WithRetryDelay sets the delay before the failed event is retried, which is doubled for each attempt.
+// The due events are moved back to the stream on polling, so they may be late up to the block duration.
+// The default is 1 second, and the failed events are retried right away if it is not positive.
+func WithRetryDelay(delay time.Duration) ServerOption {
+	return func(s *Server) {
+		s.retryDelay = delay
+	}
+}
+
+// maxRetryShift limits the doubling of the retry delay, which is up to 1024 times.
+const maxRetryShift = 10
+
+var _ transport.Server = (*Server)(nil)
+
+func NewServer(queue *Queue, dispatcher *event.Dispatcher, opts ...ServerOption) *Server {
+	s := &Server{
+		queue:       queue,
+		dispatcher:  dispatcher,
+		group:       "events",
+		consumer:    uuid.New().String(),
+		concurrency: 10, //nolint:mnd
+		block:       time.Second,
+		claimIdle:   time.Minute * 5, //nolint:mnd
+		deadLetter:  queue.stream + ":dead",
+		retryDelay:  time.Second,
+		delayed:     hashTagged(queue.stream, ":delayed"),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.concurrency <= 0 {
+		s.concurrency = 1
+	}
+	return s
+}
+
+func (s *Server) Start(ctx context.Context) error {
+	log.Infof("[Event] server starting, stream: %s, group: %s", s.queue.stream, s.group)
+	defer close(s.done)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := s.createGroup(ctx); err != nil {
+		return err
+	}
+
+	for ctx.Err() == nil {
+		if err := s.poll(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("[Event] failed to poll the stream %s: %v", s.queue.stream, err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stop stops polling, and waits for the processing events until ctx is done.
+func (s *Server) Stop(ctx context.Context) error {
+	log.Info("[Event] server stopping")
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Server) createGroup(ctx context.Context) error {
+	err := s.queue.redis.XGroupCreateMkStream(ctx, s.queue.stream, s.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) poll(ctx context.Context) error {
+	// the due retries back to the stream
+	if err := s.promote(ctx); err != nil {
+		return err
+	}
+
+	// the pending events of the crashed consumers first
+	messages, _, err := s.queue.redis.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   s.queue.stream,
+		Group:    s.group,
+		Consumer: s.consumer,
+		MinIdle:  s.claimIdle,
+		Start:    "0-0",
+		Count:    int64(s.concurrency),
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	if len(messages) == 0 {
+		streams, err := s.queue.redis.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    s.group,
+			Consumer: s.consumer,
+			Streams:  []string{s.queue.stream, ">"},
+			Count:    int64(s.concurrency),
+			Block:    s.block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		for _, stream := range streams {
+			messages = append(messages, stream.Messages...)
+		}
+	}
+
+	// the processing events are not interrupted by stopping
+	ctx = context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for _, msg := range messages {
+		wg.Add(1)
+		go func(m message) {
+			defer wg.Done()
+
+			if err := s.process(ctx, m); err != nil {
+				log.Errorf("[Event] failed to process the event %s: %v", m.id, err)
+			}
+		}(parse(msg))
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (s *Server) process(ctx context.Context, m message) error {
+	e, err := s.decode(m)
+	if err == nil {
+		err = s.dispatcher.Process(ctx, &event.Job{Listener: m.listener, Event: e})
+		if err == nil {
+			return s.queue.redis.XAck(ctx, s.queue.stream, s.group, m.id).Err()
+		}
+
+		if m.attempts < s.retries(m.listener) {
+			return s.retry(ctx, m)
+		}
+	}
+
+	log.Errorf("[Event] the event %s of the listener %s is dead: %v", m.id, m.listener, err)
+	return s.move(ctx, s.deadLetter, m, err)
+}
+
+// retry delays the next attempt of the event with the backoff, and acknowledges the original one.
+func (s *Server) retry(ctx context.Context, m message) error {
+	delay := s.retryDelay << min(m.attempts, maxRetryShift)
+	m.attempts++
+
+	if delay <= 0 {
+		return s.move(ctx, s.queue.stream, m, nil)
+	}
+
+	member, err := json.Marshal(delayedMessage{ID: m.id, Fields: m.fields()})
+	if err != nil {
+		return err
+	}
+
+	return s.queue.redis.Eval(ctx, retryScript, []string{s.delayed, s.queue.stream},
+		s.group, m.id, delay.Milliseconds(), member,
+	).Err()
+}
+
+// promote moves the due retries back to the stream.
+func (s *Server) promote(ctx context.Context) error {
+	return s.queue.redis.Eval(ctx, promoteScript, []string{s.delayed, s.queue.stream},
+		s.concurrency, s.queue.maxLen,
+	).Err()
+}
+
+// hashTagged returns the key of the name with the suffix, which is in the same cluster slot as the name.
+// The name is hash-tagged, unless it has a hash tag already.
+func hashTagged(name, suffix string) string {
+	if start := strings.IndexByte(name, '{'); start >= 0 {
+		if end := strings.IndexByte(name[start+1:], '}'); end > 0 {
+			return name + suffix
+		}
+	}
+	return "{" + name + "}" + suffix
+}
+
+func (s *Server) decode(m message) (event.Event, error) {
+	typ, ok := s.dispatcher.EventType(m.event)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, m.event)
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		v := reflect.New(typ.Elem())
+		if err := s.queue.codec.Unmarshal([]byte(m.payload), v.Interface()); err != nil {
+			return nil, err
+		}
+		return v.Interface().(event.Event), nil
+	}
+
+	v := reflect.New(typ)
+	if err := s.queue.codec.Unmarshal([]byte(m.payload), v.Interface()); err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface().(event.Event), nil
+}
+
+func (s *Server) retries(name string) int {
+	listener, ok := s.dispatcher.QueuedListener(name)
+	if !ok {
+		return 0
+	}
+	if r, ok := listener.(features.Retriable); ok {
+		return r.Retries()
+	}
+	return 0
+}
+
+// move adds the event to the stream, and acknowledges the original one.
+func (s *Server) move(ctx context.Context, stream string, m message, err error) error {
+	values := m.values()
+	if err != nil {
+		values[fieldError] = err.Error()
+	}
+
+	_, err = s.queue.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, s.queue.args(stream, values))
+		pipe.XAck(ctx, s.queue.stream, s.group, m.id)
+		return nil
+	})
+	return err
+}
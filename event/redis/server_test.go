@@ -0,0 +1,335 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/event"
+	"github.com/go-kratos-ecosystem/components/v2/features"
+)
+
+var ctx = context.Background()
+
+func newRedis(t *testing.T) redis.UniversalClient {
+	rdb := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+	})
+	t.Cleanup(func() {
+		_ = rdb.Close()
+	})
+	return rdb
+}
+
+type orderCreated struct {
+	ID int `json:"id"`
+}
+
+func (e *orderCreated) Event() any {
+	return orderCreated{}
+}
+
+type orderListener struct {
+	features.QueueFeature
+	*features.RetryFeature
+	*features.NamedFeature
+
+	err   error
+	ids   []int
+	calls int
+	mu    sync.Mutex
+}
+
+func (l *orderListener) Listen() []event.Event {
+	return []event.Event{&orderCreated{}}
+}
+
+func (l *orderListener) Handle(_ context.Context, e event.Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls++
+	if l.err != nil {
+		return l.err
+	}
+	l.ids = append(l.ids, e.(*orderCreated).ID)
+	return nil
+}
+
+func (l *orderListener) stats() (int, []int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.calls, append([]int(nil), l.ids...)
+}
+
+type syncListener struct {
+	ids []int
+}
+
+func (l *syncListener) Listen() []event.Event {
+	return []event.Event{&orderCreated{}}
+}
+
+func (l *syncListener) Handle(e event.Event) {
+	l.ids = append(l.ids, e.(*orderCreated).ID)
+}
+
+func TestServer(t *testing.T) {
+	var (
+		client  = newRedis(t)
+		stream  = "kratos:event:stream"
+		errTest = errors.New("test")
+	)
+	client.Del(ctx, stream, stream+":dead", hashTagged(stream, ":delayed"))
+	t.Cleanup(func() {
+		client.Del(ctx, stream, stream+":dead", hashTagged(stream, ":delayed"))
+	})
+
+	var (
+		queue   = NewQueue(client, WithStream(stream), WithMaxLen(1000))
+		d       = event.NewDispatcher(event.WithQueue(queue))
+		succeed = &orderListener{
+			RetryFeature: features.NewRetryFeature(0),
+			NamedFeature: features.NewNamedFeature("succeed"),
+		}
+		failed = &orderListener{
+			RetryFeature: features.NewRetryFeature(2),
+			NamedFeature: features.NewNamedFeature("failed"),
+			err:          errTest,
+		}
+		inline = &syncListener{}
+	)
+	d.AddContextListener(succeed, failed)
+	d.AddListener(inline)
+
+	// the queued listeners are enqueued, the others run right away
+	assert.NoError(t, d.DispatchContext(ctx, &orderCreated{ID: 1}))
+	d.DispatchAsync(&orderCreated{ID: 2})
+	d.Wait()
+
+	assert.Equal(t, []int{1, 2}, inline.ids)
+	assert.Equal(t, int64(4), client.XLen(ctx, stream).Val())
+
+	calls, _ := succeed.stats()
+	assert.Equal(t, 0, calls)
+
+	// processed by the server
+	srv := NewServer(queue, d, WithBlock(time.Millisecond*100), WithConcurrency(2), WithRetryDelay(time.Millisecond))
+	go func() {
+		assert.NoError(t, srv.Start(ctx))
+	}()
+
+	assert.Eventually(t, func() bool {
+		calls, _ := failed.stats()
+		return calls == 6 && client.XLen(ctx, stream+":dead").Val() == 2
+	}, time.Second*5, time.Millisecond*50)
+
+	calls, ids := succeed.stats()
+	assert.Equal(t, 2, calls)
+	assert.ElementsMatch(t, []int{1, 2}, ids)
+
+	// dead-lettered after 2 retries, with the error
+	dead := client.XRange(ctx, stream+":dead", "-", "+").Val()
+	assert.Len(t, dead, 2)
+	assert.Equal(t, "failed", dead[0].Values[fieldListener])
+	assert.Equal(t, "2", dead[0].Values[fieldAttempts])
+	assert.Equal(t, "test", dead[0].Values[fieldError])
+
+	// all acknowledged
+	pending := client.XPending(ctx, stream, "events").Val()
+	assert.Equal(t, int64(0), pending.Count)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	assert.NoError(t, srv.Stop(ctx))
+}
+
+func TestServer_Unknown(t *testing.T) {
+	var (
+		client = newRedis(t)
+		stream = "kratos:event:unknown"
+	)
+	client.Del(ctx, stream, stream+":dead", hashTagged(stream, ":delayed"))
+	t.Cleanup(func() {
+		client.Del(ctx, stream, stream+":dead", hashTagged(stream, ":delayed"))
+	})
+
+	queue := NewQueue(client, WithStream(stream))
+	assert.NoError(t, queue.Enqueue(ctx, &event.Job{Listener: "unknown", Event: &orderCreated{ID: 1}}))
+
+	srv := NewServer(queue, event.NewDispatcher(), WithBlock(time.Millisecond*100))
+	go func() {
+		assert.NoError(t, srv.Start(ctx))
+	}()
+
+	assert.Eventually(t, func() bool {
+		return client.XLen(ctx, stream+":dead").Val() == 1
+	}, time.Second*5, time.Millisecond*50)
+
+	dead := client.XRange(ctx, stream+":dead", "-", "+").Val()
+	assert.Contains(t, dead[0].Values[fieldError], ErrUnknownEvent.Error())
+
+	assert.NoError(t, srv.Stop(ctx))
+}
+
+func TestServer_RetryDelay(t *testing.T) {
+	var (
+		client = newRedis(t)
+		stream = "kratos:event:retry"
+	)
+	client.Del(ctx, stream, stream+":dead", hashTagged(stream, ":delayed"))
+	t.Cleanup(func() {
+		client.Del(ctx, stream, stream+":dead", hashTagged(stream, ":delayed"))
+	})
+
+	var (
+		queue  = NewQueue(client, WithStream(stream))
+		d      = event.NewDispatcher(event.WithQueue(queue))
+		failed = &orderListener{
+			RetryFeature: features.NewRetryFeature(2),
+			NamedFeature: features.NewNamedFeature("failed"),
+			err:          errors.New("test"),
+		}
+	)
+	d.AddContextListener(failed)
+	assert.NoError(t, d.DispatchContext(ctx, &orderCreated{ID: 1}))
+
+	// retried after 100ms and 200ms
+	now := time.Now()
+	srv := NewServer(queue, d, WithBlock(time.Millisecond*50), WithRetryDelay(time.Millisecond*100))
+	go func() {
+		assert.NoError(t, srv.Start(ctx))
+	}()
+
+	assert.Eventually(t, func() bool {
+		return client.XLen(ctx, stream+":dead").Val() == 1
+	}, time.Second*5, time.Millisecond*20)
+	assert.GreaterOrEqual(t, time.Since(now), time.Millisecond*300)
+
+	calls, _ := failed.stats()
+	assert.Equal(t, 3, calls)
+
+	assert.NoError(t, srv.Stop(ctx))
+}
+
+type auditListener struct {
+	features.QueueFeature
+	*features.NamedFeature
+
+	ids chan int
+}
+
+func (l *auditListener) Listen() []event.Event {
+	return []event.Event{event.Implements[*orderCreated]()}
+}
+
+func (l *auditListener) Handle(_ context.Context, e event.Event) error {
+	l.ids <- e.(*orderCreated).ID
+	return nil
+}
+
+func TestServer_Consumer(t *testing.T) {
+	var (
+		client = newRedis(t)
+		stream = "kratos:event:consumer"
+	)
+	client.Del(ctx, stream, stream+":dead", hashTagged(stream, ":delayed"))
+	t.Cleanup(func() {
+		client.Del(ctx, stream, stream+":dead", hashTagged(stream, ":delayed"))
+	})
+
+	// the producer
+	queue := NewQueue(client, WithStream(stream))
+	producer := event.NewDispatcher(event.WithQueue(queue))
+	producer.AddContextListener(&auditListener{NamedFeature: features.NewNamedFeature("audit")})
+	assert.NoError(t, producer.DispatchContext(ctx, &orderCreated{ID: 1}))
+
+	// the consumer only, which never dispatches the event
+	audit := &auditListener{NamedFeature: features.NewNamedFeature("audit"), ids: make(chan int, 1)}
+	consumer := event.NewDispatcher()
+	consumer.AddContextListener(audit)
+	consumer.RegisterEvent(&orderCreated{})
+
+	srv := NewServer(queue, consumer, WithBlock(time.Millisecond*100))
+	go func() {
+		assert.NoError(t, srv.Start(ctx))
+	}()
+
+	select {
+	case id := <-audit.ids:
+		assert.Equal(t, 1, id)
+	case <-time.After(time.Second * 5):
+		t.Fatal("the event is not processed")
+	}
+	assert.Equal(t, int64(0), client.XLen(ctx, stream+":dead").Val())
+
+	assert.NoError(t, srv.Stop(ctx))
+}
+
+func TestServer_RetryBatch(t *testing.T) {
+	var (
+		client  = newRedis(t)
+		stream  = "kratos:event:batch"
+		delayed = hashTagged(stream, ":delayed")
+	)
+	client.Del(ctx, stream, stream+":dead", delayed)
+	t.Cleanup(func() {
+		client.Del(ctx, stream, stream+":dead", delayed)
+	})
+
+	var (
+		queue  = NewQueue(client, WithStream(stream))
+		d      = event.NewDispatcher(event.WithQueue(queue))
+		failed = &orderListener{
+			RetryFeature: features.NewRetryFeature(1),
+			NamedFeature: features.NewNamedFeature("failed"),
+			err:          errors.New("test"),
+		}
+		succeed = &orderListener{
+			RetryFeature: features.NewRetryFeature(0),
+			NamedFeature: features.NewNamedFeature("succeed"),
+		}
+	)
+	d.AddContextListener(failed, succeed)
+
+	// the failing event and the succeeding event in the same batch
+	assert.NoError(t, d.DispatchContext(ctx, &orderCreated{ID: 1}))
+
+	now := time.Now()
+	srv := NewServer(queue, d, WithBlock(time.Millisecond*50), WithRetryDelay(time.Second))
+	go func() {
+		assert.NoError(t, srv.Start(ctx))
+	}()
+
+	// the succeeding one is not held up by the delayed retry
+	assert.Eventually(t, func() bool {
+		calls, _ := succeed.stats()
+		return calls == 1
+	}, time.Millisecond*500, time.Millisecond*10)
+
+	// the failed one is acknowledged, and delayed
+	assert.Eventually(t, func() bool {
+		return client.ZCard(ctx, delayed).Val() == 1
+	}, time.Millisecond*500, time.Millisecond*10)
+	assert.Equal(t, int64(0), client.XPending(ctx, stream, "events").Val().Count)
+
+	// retried after the delay, and then dead
+	assert.Eventually(t, func() bool {
+		return client.XLen(ctx, stream+":dead").Val() == 1
+	}, time.Second*5, time.Millisecond*20)
+	assert.GreaterOrEqual(t, time.Since(now), time.Second)
+
+	calls, _ := failed.stats()
+	assert.Equal(t, 2, calls)
+	calls, _ = succeed.stats()
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, int64(0), client.ZCard(ctx, delayed).Val())
+
+	assert.NoError(t, srv.Stop(ctx))
+}
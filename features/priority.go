@@ -0,0 +1,17 @@
+package features
+
+type Prioritized interface {
+	Priority() int
+}
+
+type PriorityFeature struct {
+	priority int
+}
+
+func NewPriorityFeature(priority int) *PriorityFeature {
+	return &PriorityFeature{priority: priority}
+}
+
+func (p *PriorityFeature) Priority() int {
+	return p.priority
+}
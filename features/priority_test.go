@@ -0,0 +1,12 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriority(t *testing.T) {
+	assert.Equal(t, 10, NewPriorityFeature(10).Priority())
+	assert.Equal(t, -1, NewPriorityFeature(-1).Priority())
+}
@@ -0,0 +1,11 @@
+package features
+
+type Queueable interface {
+	Queued() bool
+}
+
+type QueueFeature struct{}
+
+func (*QueueFeature) Queued() bool {
+	return true
+}
@@ -0,0 +1,13 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueue(t *testing.T) {
+	f := QueueFeature{}
+
+	assert.True(t, f.Queued())
+}
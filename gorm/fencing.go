@@ -0,0 +1,46 @@
+package gorm
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrStaleToken = errors.New("gorm: the fencing token is stale")
+
+// FencingScope limits the rows to the ones whose fencing token is not newer than the token,
+// so the writes of a previous lock owner are rejected after a newer owner has written.
+//
+//	db.Model(&order).Scopes(FencingScope("lock_token", locker.Token(owner))).Updates(...)
+func FencingScope(column string, token int64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Lte{Column: clause.Column{Name: column}, Value: token})
+	}
+}
+
+// UpdateFenced updates the rows with the values, and stores the fencing token into the column.
+// It returns ErrStaleToken if no row is updated, e.g. the rows have been written with a newer token.
+//
+// With MySQL, the unchanged rows are not counted as updated unless clientFoundRows=true is set in the DSN.
+//
+//	err := UpdateFenced(db.Model(&Order{}).Where("id = ?", id), "lock_token", locker.Token(owner), map[string]any{
+//		"status": "paid",
+//	})
+func UpdateFenced(db *gorm.DB, column string, token int64, values map[string]any) error {
+	updates := make(map[string]any, len(values)+1)
+	for k, v := range values {
+		updates[k] = v
+	}
+	updates[column] = token
+
+	result := db.Scopes(FencingScope(column, token)).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleToken
+	}
+
+	return nil
+}
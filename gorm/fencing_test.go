@@ -0,0 +1,86 @@
+package gorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+var dsn = "gorm:gorm@tcp(localhost:3306)/gorm?charset=utf8&parseTime=True&loc=Local"
+
+type order struct {
+	ID        uint
+	Status    string
+	LockToken int64
+}
+
+func newDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "gorm:gorm@tcp(localhost:3306)/gorm",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	assert.NoError(t, err)
+	return db
+}
+
+func TestFencingScope(t *testing.T) {
+	db := newDryRunDB(t)
+
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&order{}).Where("id = ?", 1).Scopes(FencingScope("lock_token", 3)).
+			Updates(map[string]any{"status": "paid", "lock_token": 3})
+	})
+	assert.Equal(t, "UPDATE `orders` SET `lock_token`=3,`status`='paid' WHERE id = 1 AND `lock_token` <= 3", sql)
+}
+
+type fencedOrder struct {
+	ID        uint
+	Status    string
+	LockToken int64
+}
+
+func newDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal("failed to connect database, got error", err)
+	}
+
+	assert.NoError(t, db.Migrator().DropTable(&fencedOrder{}))
+	assert.NoError(t, db.AutoMigrate(&fencedOrder{}))
+
+	return db
+}
+
+func TestUpdateFenced(t *testing.T) {
+	db := newDB(t)
+	assert.NoError(t, db.Create(&fencedOrder{ID: 1, Status: "created", LockToken: 1}).Error)
+
+	update := func(token int64, status string) error {
+		return UpdateFenced(db.Model(&fencedOrder{}).Where("id = ?", 1), "lock_token", token, map[string]any{
+			"status": status,
+		})
+	}
+	find := func() fencedOrder {
+		var o fencedOrder
+		assert.NoError(t, db.First(&o, 1).Error)
+		return o
+	}
+
+	// the newer token updates the row
+	assert.NoError(t, update(3, "paid"))
+	assert.Equal(t, fencedOrder{ID: 1, Status: "paid", LockToken: 3}, find())
+
+	// the older token is rejected
+	assert.ErrorIs(t, update(2, "canceled"), ErrStaleToken)
+	assert.Equal(t, fencedOrder{ID: 1, Status: "paid", LockToken: 3}, find())
+
+	// the same token updates the row again
+	assert.NoError(t, update(3, "shipped"))
+	assert.Equal(t, fencedOrder{ID: 1, Status: "shipped", LockToken: 3}, find())
+}
@@ -0,0 +1,16 @@
+package locker
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a new context with the owner, so the reentrant lockers reacquire the lock with it.
+func NewContext(ctx context.Context, owner Owner) context.Context {
+	return context.WithValue(ctx, contextKey{}, owner)
+}
+
+// FromContext returns the owner in the context.
+func FromContext(ctx context.Context) (Owner, bool) {
+	owner, ok := ctx.Value(contextKey{}).(Owner)
+	return owner, ok
+}
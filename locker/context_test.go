@@ -0,0 +1,20 @@
+package locker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+
+	owner := NewOwner(NoopLocker{})
+	got, ok := FromContext(NewContext(ctx, owner))
+	assert.True(t, ok)
+	assert.Same(t, owner, got)
+}
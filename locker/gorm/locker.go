@@ -0,0 +1,217 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// Lock is the row of a lock in the locks table.
+type Lock struct {
+	Name      string `gorm:"column:name;primaryKey;size:191"`
+	Owner     string `gorm:"column:owner;size:191;not null"`
+	ExpiresAt int64  `gorm:"column:expires_at;not null;index"` // unix milliseconds, 0 means forever
+}
+
+// Locker is the locker backed by a table of the database, for the teams without redis.
+//
+// The lock is acquired by upserting the row of the name, which is ignored if the row exists.
+// The expired row is deleted before inserting, so the locks of the crashed owners are reclaimed.
+// The expiration depends on the clocks of the clients, which should be synchronized.
+type Locker struct {
+	db    *gorm.DB
+	table string
+	name  string
+	ttl   time.Duration
+	sleep time.Duration // for Until
+}
+
+type Option func(*Locker)
+
+// WithTable sets the table of the locks, the default is locks.
+func WithTable(table string) Option {
+	return func(l *Locker) {
+		l.table = table
+	}
+}
+
+func WithName(name string) Option {
+	return func(l *Locker) {
+		l.name = name
+	}
+}
+
+// WithTTL sets the ttl of the lock, 0 means the lock never expires.
+func WithTTL(ttl time.Duration) Option {
+	return func(l *Locker) {
+		l.ttl = ttl
+	}
+}
+
+func WithSleep(sleep time.Duration) Option {
+	return func(l *Locker) {
+		l.sleep = sleep
+	}
+}
+
+var _ locker.Locker = (*Locker)(nil)
+
+func NewLocker(db *gorm.DB, opts ...Option) *Locker {
+	l := &Locker{
+		db:    db,
+		table: "locks",
+		name:  uuid.New().String(),
+		ttl:   time.Second * 10,       //nolint:mnd
+		sleep: time.Millisecond * 100, //nolint:mnd
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Migrate creates the table of the locks.
+func (l *Locker) Migrate(ctx context.Context) error {
+	return l.query(ctx).AutoMigrate(&Lock{})
+}
+
+func (l *Locker) Try(ctx context.Context, fn func()) error {
+	owner, err := l.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (l *Locker) Until(ctx context.Context, timeout time.Duration, fn func()) error {
+	deadline := time.Now().Add(timeout)
+	owner := locker.NewOwner(l)
+
+	for {
+		if ok, err := l.acquire(ctx, owner); err != nil {
+			return err
+		} else if ok {
+			break
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return locker.ErrTimeout
+		}
+
+		timer := time.NewTimer(min(l.sleep, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (l *Locker) Get(ctx context.Context) (locker.Owner, error) {
+	owner := locker.NewOwner(l)
+	ok, err := l.acquire(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, locker.ErrLocked
+	}
+	return owner, nil
+}
+
+func (l *Locker) Release(ctx context.Context, owner locker.Owner) error {
+	result := l.query(ctx).
+		Where("name = ? AND owner = ?", l.name, owner.Name()).
+		Scopes(l.unexpired()).
+		Delete(&Lock{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return locker.ErrNotLocked
+	}
+	return nil
+}
+
+func (l *Locker) ForceRelease(ctx context.Context) error {
+	return l.query(ctx).Where("name = ?", l.name).Delete(&Lock{}).Error
+}
+
+func (l *Locker) LockedOwner(ctx context.Context) (locker.Owner, error) {
+	var lock Lock
+	err := l.query(ctx).Where("name = ?", l.name).Scopes(l.unexpired()).Take(&lock).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, locker.ErrNotLocked
+	} else if err != nil {
+		return nil, err
+	}
+
+	return locker.NewOwner(l, locker.WithOwnerName(lock.Owner)), nil
+}
+
+func (l *Locker) acquire(ctx context.Context, owner locker.Owner) (bool, error) {
+	now := time.Now().UnixMilli()
+
+	// reclaim the expired lock
+	if err := l.query(ctx).
+		Where("name = ? AND expires_at > 0 AND expires_at <= ?", l.name, now).
+		Delete(&Lock{}).Error; err != nil {
+		return false, err
+	}
+
+	var expiresAt int64
+	if l.ttl > 0 {
+		expiresAt = now + l.ttl.Milliseconds()
+	}
+
+	if err := l.query(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&Lock{
+		Name:      l.name,
+		Owner:     owner.Name(),
+		ExpiresAt: expiresAt,
+	}).Error; err != nil {
+		return false, err
+	}
+
+	// the affected rows of the ignored insert differ between the databases, so the owner is checked instead
+	var lock Lock
+	err := l.query(ctx).Where("name = ?", l.name).Take(&lock).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return lock.Owner == owner.Name(), nil
+}
+
+func (l *Locker) unexpired() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("expires_at = 0 OR expires_at > ?", time.Now().UnixMilli())
+	}
+}
+
+func (l *Locker) query(ctx context.Context) *gorm.DB {
+	return l.db.WithContext(ctx).Table(l.table)
+}
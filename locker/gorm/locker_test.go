@@ -0,0 +1,113 @@
+package gorm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+var (
+	ctx = context.Background()
+	dsn = "gorm:gorm@tcp(localhost:3306)/gorm?charset=utf8&parseTime=True&loc=Local"
+)
+
+func newDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal("failed to connect database, got error", err)
+	}
+
+	assert.NoError(t, db.Migrator().DropTable("test_locks"))
+	assert.NoError(t, NewLocker(db, WithTable("test_locks")).Migrate(ctx))
+
+	return db
+}
+
+func TestLocker_Try(t *testing.T) {
+	db := newDB(t)
+
+	var (
+		wg      sync.WaitGroup
+		success int64
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := NewLocker(db, WithTable("test_locks"), WithName("try")).Try(ctx, func() {
+				time.Sleep(time.Millisecond * 100)
+			})
+			if err == nil {
+				atomic.AddInt64(&success, 1)
+			} else {
+				assert.ErrorIs(t, err, locker.ErrLocked)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), success)
+}
+
+func TestLocker_Until(t *testing.T) {
+	l := NewLocker(newDB(t), WithTable("test_locks"), WithName("until"), WithSleep(time.Millisecond*10))
+
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, l.Until(ctx, time.Millisecond*50, func() {}), locker.ErrTimeout)
+
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		assert.NoError(t, owner.Release(ctx))
+	}()
+
+	var called bool
+	assert.NoError(t, l.Until(ctx, time.Second, func() {
+		called = true
+	}))
+	assert.True(t, called)
+}
+
+func TestLocker_GetAndRelease(t *testing.T) {
+	l := NewLocker(newDB(t), WithTable("test_locks"), WithName("get"), WithTTL(time.Millisecond*200))
+
+	owner1, err := l.Get(ctx)
+	assert.NoError(t, err)
+
+	owner, err := l.LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, owner1.Name(), owner.Name())
+
+	_, err = l.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	assert.ErrorIs(t, l.Release(ctx, locker.NewOwner(l)), locker.ErrNotLocked)
+	assert.NoError(t, owner1.Release(ctx))
+
+	_, err = l.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+
+	// expired
+	owner2, err := l.Get(ctx)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 250)
+
+	_, err = l.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+	owner3, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, owner2.Release(ctx), locker.ErrNotLocked)
+
+	// force release
+	assert.NoError(t, l.ForceRelease(ctx))
+	assert.ErrorIs(t, owner3.Release(ctx), locker.ErrNotLocked)
+}
@@ -10,6 +10,7 @@ var (
 	ErrLocked    = errors.New("locker: the locker is locked")
 	ErrTimeout   = errors.New("locker: the locker is timeout")
 	ErrNotLocked = errors.New("locker: the locker is not locked")
+	ErrLeaseLost = errors.New("locker: the lease of the locker is lost")
 )
 
 type Locker interface {
@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// Store keeps the locks in memory, the lockers with the same store and name exclude each other.
+type Store struct {
+	locks map[string]*lock
+	mu    sync.Mutex
+}
+
+type lock struct {
+	owner    string
+	expiry   time.Time // zero means forever
+	released chan struct{}
+}
+
+func (l *lock) expired() bool {
+	return !l.expiry.IsZero() && !time.Now().Before(l.expiry)
+}
+
+// New returns a new store of the locks.
+func New() *Store {
+	return &Store{
+		locks: make(map[string]*lock),
+	}
+}
+
+// acquire acquires the lock for the owner. If the lock is held by others,
+// it returns the channel which is closed when the lock is released.
+func (s *Store) acquire(name, owner string, ttl time.Duration) (bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l, ok := s.locks[name]; ok {
+		if !l.expired() {
+			return false, l.released
+		}
+		close(l.released)
+	}
+
+	l := &lock{
+		owner:    owner,
+		released: make(chan struct{}),
+	}
+	if ttl > 0 {
+		l.expiry = time.Now().Add(ttl)
+	}
+	s.locks[name] = l
+
+	return true, nil
+}
+
+func (s *Store) release(name, owner string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.locks[name]
+	if !ok {
+		return false
+	}
+
+	if l.expired() {
+		delete(s.locks, name)
+		close(l.released)
+		return false
+	}
+
+	if owner != "" && l.owner != owner {
+		return false
+	}
+
+	delete(s.locks, name)
+	close(l.released)
+
+	return true
+}
+
+func (s *Store) owner(name string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.locks[name]
+	if !ok || l.expired() {
+		return "", false
+	}
+	return l.owner, true
+}
+
+// Locker is the in-memory locker with the real mutual exclusion and ttl,
+// for the tests and the single-node deployments.
+type Locker struct {
+	store *Store
+	name  string
+	ttl   time.Duration
+	sleep time.Duration // for Until, the waiters are woken on release as well
+}
+
+type Option func(*Locker)
+
+func WithName(name string) Option {
+	return func(l *Locker) {
+		l.name = name
+	}
+}
+
+// WithTTL sets the ttl of the lock, 0 means the lock never expires.
+func WithTTL(ttl time.Duration) Option {
+	return func(l *Locker) {
+		l.ttl = ttl
+	}
+}
+
+func WithSleep(sleep time.Duration) Option {
+	return func(l *Locker) {
+		l.sleep = sleep
+	}
+}
+
+var _ locker.Locker = (*Locker)(nil)
+
+func NewLocker(store *Store, opts ...Option) *Locker {
+	l := &Locker{
+		store: store,
+		name:  uuid.New().String(),
+		ttl:   time.Second * 10,      //nolint:mnd
+		sleep: time.Millisecond * 10, //nolint:mnd
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+func (l *Locker) Try(ctx context.Context, fn func()) error {
+	owner, err := l.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (l *Locker) Until(ctx context.Context, timeout time.Duration, fn func()) error {
+	deadline := time.Now().Add(timeout)
+	owner := locker.NewOwner(l)
+
+	for {
+		ok, released := l.store.acquire(l.name, owner.Name(), l.ttl)
+		if ok {
+			break
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return locker.ErrTimeout
+		}
+
+		timer := time.NewTimer(min(l.sleep, remaining))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-released:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (l *Locker) Get(context.Context) (locker.Owner, error) {
+	owner := locker.NewOwner(l)
+	if ok, _ := l.store.acquire(l.name, owner.Name(), l.ttl); !ok {
+		return nil, locker.ErrLocked
+	}
+	return owner, nil
+}
+
+func (l *Locker) Release(_ context.Context, owner locker.Owner) error {
+	if !l.store.release(l.name, owner.Name()) {
+		return locker.ErrNotLocked
+	}
+	return nil
+}
+
+func (l *Locker) ForceRelease(context.Context) error {
+	l.store.release(l.name, "")
+	return nil
+}
+
+func (l *Locker) LockedOwner(context.Context) (locker.Owner, error) {
+	name, ok := l.store.owner(l.name)
+	if !ok {
+		return nil, locker.ErrNotLocked
+	}
+	return locker.NewOwner(l, locker.WithOwnerName(name)), nil
+}
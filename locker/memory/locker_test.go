@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+var ctx = context.Background()
+
+func TestLocker_Try(t *testing.T) {
+	store := New()
+
+	var (
+		wg      sync.WaitGroup
+		running int64
+		success int64
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// the lockers with the same store and name exclude each other
+			err := NewLocker(store, WithName("try")).Try(ctx, func() {
+				assert.Equal(t, int64(1), atomic.AddInt64(&running, 1))
+				time.Sleep(time.Millisecond * 50)
+				atomic.AddInt64(&running, -1)
+			})
+			if err == nil {
+				atomic.AddInt64(&success, 1)
+			} else {
+				assert.ErrorIs(t, err, locker.ErrLocked)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), success)
+
+	// another store
+	assert.NoError(t, NewLocker(New(), WithName("try")).Try(ctx, func() {}))
+}
+
+func TestLocker_Until(t *testing.T) {
+	l := NewLocker(New(), WithName("until"), WithSleep(time.Second))
+
+	var (
+		wg      sync.WaitGroup
+		running int64
+		count   int64
+	)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, l.Until(ctx, time.Second*5, func() {
+				assert.Equal(t, int64(1), atomic.AddInt64(&running, 1))
+				time.Sleep(time.Millisecond * 10)
+				atomic.AddInt64(&running, -1)
+				atomic.AddInt64(&count, 1)
+			}))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(5), count)
+	// woken by the release, instead of the sleep
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestLocker_Until_TimeoutAndContext(t *testing.T) {
+	l := NewLocker(New(), WithName("until:timeout"))
+
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, l.Until(ctx, time.Millisecond*50, func() {}), locker.ErrTimeout)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, l.Until(cctx, time.Second, func() {}), context.Canceled)
+
+	assert.NoError(t, owner.Release(ctx))
+}
+
+func TestLocker_GetAndRelease(t *testing.T) {
+	l := NewLocker(New(), WithName("get"), WithTTL(time.Millisecond*100))
+
+	owner1, err := l.Get(ctx)
+	assert.NoError(t, err)
+
+	owner, err := l.LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, owner1.Name(), owner.Name())
+
+	_, err = l.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	assert.ErrorIs(t, l.Release(ctx, locker.NewOwner(l)), locker.ErrNotLocked)
+	assert.NoError(t, owner1.Release(ctx))
+	assert.ErrorIs(t, owner1.Release(ctx), locker.ErrNotLocked)
+
+	_, err = l.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+
+	// expired
+	owner2, err := l.Get(ctx)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 150)
+
+	_, err = l.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+	owner3, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, owner2.Release(ctx), locker.ErrNotLocked)
+
+	// force release
+	assert.NoError(t, l.ForceRelease(ctx))
+	assert.ErrorIs(t, owner3.Release(ctx), locker.ErrNotLocked)
+
+	// never expires
+	l = NewLocker(New(), WithTTL(0))
+	_, err = l.Get(ctx)
+	assert.NoError(t, err)
+	time.Sleep(time.Millisecond * 10)
+	_, err = l.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+}
@@ -14,8 +14,18 @@ type Owner interface {
 	Release(ctx context.Context) error
 }
 
+// Fenced is implemented by the owners with a fencing token.
+//
+// The token is issued by the locker on acquisition, and increases monotonically for each lock,
+// so the downstream storage can reject the writes of a previous owner whose lease is expired.
+type Fenced interface {
+	// Token returns the fencing token, 0 means no token is issued.
+	Token() int64
+}
+
 type owner struct {
 	name   string
+	token  int64
 	locker Locker
 }
 
@@ -27,6 +37,12 @@ func WithOwnerName(name string) OwnerOption {
 	}
 }
 
+func WithOwnerToken(token int64) OwnerOption {
+	return func(o *owner) {
+		o.token = token
+	}
+}
+
 func NewOwner(locker Locker, opts ...OwnerOption) Owner {
 	o := &owner{
 		name:   uuid.New().String(),
@@ -42,6 +58,18 @@ func (o *owner) Name() string {
 	return o.name
 }
 
+func (o *owner) Token() int64 {
+	return o.token
+}
+
 func (o *owner) Release(ctx context.Context) error {
 	return o.locker.Release(ctx, o)
 }
+
+// Token returns the fencing token of the owner, 0 means the owner has no token.
+func Token(owner Owner) int64 {
+	if fenced, ok := owner.(Fenced); ok {
+		return fenced.Token()
+	}
+	return 0
+}
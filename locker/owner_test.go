@@ -12,3 +12,8 @@ func TestOwner_owner(t *testing.T) {
 	assert.Equal(t, "test", o.Name())
 	assert.NoError(t, o.Release(context.Background()))
 }
+
+func TestOwner_Token(t *testing.T) {
+	assert.Zero(t, Token(NewOwner(NoopLocker{})))
+	assert.Equal(t, int64(10), Token(NewOwner(NoopLocker{}, WithOwnerToken(10))))
+}
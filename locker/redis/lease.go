@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// Lease is the owner of the lock guarded by the watchdog.
+//
+// The watchdog keeps extending the ttl of the lock until the lease is released or lost.
+// The lease is lost when the lock is taken by others, e.g. force released or expired,
+// or when the lock can not be extended before it expires.
+type Lease struct {
+	locker.Owner
+
+	locker *Locker
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	once   sync.Once
+	done   chan struct{}
+}
+
+var (
+	_ locker.Owner  = (*Lease)(nil)
+	_ locker.Fenced = (*Lease)(nil)
+)
+
+// lease starts the watchdog of the owner.
+// The context of the lease keeps the values of ctx, but is not cancelled with it.
+func (l *Locker) lease(ctx context.Context, owner locker.Owner) *Lease {
+	leaseCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+
+	lease := &Lease{
+		Owner:  owner,
+		locker: l,
+		ctx:    leaseCtx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go lease.watch()
+
+	return lease
+}
+
+// Context returns the context, which is cancelled when the lease is released or lost.
+// If the lease is lost, the cause of the context is locker.ErrLeaseLost.
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// Lost reports whether the lease is lost.
+func (l *Lease) Lost() bool {
+	return context.Cause(l.ctx) == locker.ErrLeaseLost //nolint:errorlint
+}
+
+// Token returns the fencing token of the owner, see WithFencing.
+func (l *Lease) Token() int64 {
+	return locker.Token(l.Owner)
+}
+
+func (l *Lease) Release(ctx context.Context) error {
+	return l.locker.Release(ctx, l)
+}
+
+// stop stops the watchdog and waits for it to exit, so the lock is not extended after the release.
+func (l *Lease) stop() {
+	l.once.Do(func() {
+		close(l.done)
+	})
+	<-l.ctx.Done()
+}
+
+func (l *Lease) watch() {
+	interval := l.locker.interval
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	extended := time.Now()
+	for {
+		select {
+		case <-l.done:
+			l.cancel(nil)
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(l.ctx, interval)
+		ok, err := l.locker.extend(ctx, l.Owner)
+		cancel()
+
+		switch {
+		case err == nil && !ok:
+			l.cancel(locker.ErrLeaseLost)
+			return
+		case err == nil:
+			extended = time.Now()
+		case time.Since(extended) >= l.locker.ttl:
+			// the lock has been expired since the last extension
+			l.cancel(locker.ErrLeaseLost)
+			return
+		}
+	}
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,11 +21,48 @@ else
     return 0
 end`
 
+// extendScript is a Lua script to extend the ttl of a lock in an atomic way.
+//
+//	KEYS[1] is the lock key
+//	ARGV[1] is the lock value
+//	ARGV[2] is the ttl in milliseconds
+const extendScript = `if redis.call("get",KEYS[1]) == ARGV[1] then
+    return redis.call("pexpire",KEYS[1],ARGV[2])
+else
+    return 0
+end`
+
+// fencedAcquireScript is a Lua script to acquire a lock and issue the fencing token in an atomic way.
+// The token key never expires, so the tokens increase monotonically.
+//
+//	KEYS[1] is the lock key
+//	KEYS[2] is the token key
+//	ARGV[1] is the lock value
+//	ARGV[2] is the ttl in milliseconds, the lock never expires if it is not positive
+const fencedAcquireScript = `local ok
+if tonumber(ARGV[2]) > 0 then
+    ok = redis.call("set",KEYS[1],ARGV[1],"nx","px",ARGV[2])
+else
+    ok = redis.call("set",KEYS[1],ARGV[1],"nx")
+end
+if ok then
+    return redis.call("incr",KEYS[2])
+end
+return 0`
+
 type Locker struct {
 	redis redis.UniversalClient
 	name  string
 	ttl   time.Duration
-	sleep time.Duration // for Until
+	sleep time.Duration // for Until, the initial backoff
+
+	maxSleep     time.Duration // for Until, the maximum backoff
+	notification Notification  // for Until
+
+	watchdog bool
+	interval time.Duration // for the watchdog
+
+	fencing bool
 }
 
 type Option func(*Locker)
@@ -41,27 +79,94 @@ func WithTTL(ttl time.Duration) Option {
 	}
 }
 
+// WithSleep sets the initial backoff of Until, which is doubled with jitter after each attempt.
 func WithSleep(sleep time.Duration) Option {
 	return func(l *Locker) {
 		l.sleep = sleep
 	}
 }
 
+// WithMaxSleep sets the maximum backoff of Until.
+func WithMaxSleep(sleep time.Duration) Option {
+	return func(l *Locker) {
+		l.maxSleep = sleep
+	}
+}
+
+// WithNotification wakes the waiters of Until right away when the lock is released,
+// the backoff is still used for the locks released by expiration.
+func WithNotification(notification Notification) Option {
+	return func(l *Locker) {
+		l.notification = notification
+	}
+}
+
+// WithWatchdog enables the watchdog, which extends the ttl of the lock every interval
+// while the owner holds the lock. If the interval is not positive or not less than the ttl,
+// a third of the ttl is used. The ttl is at least minWatchdogTTL with the watchdog.
+//
+// The owners returned by Get are *Lease, whose context is cancelled when the lease is lost.
+func WithWatchdog(interval time.Duration) Option {
+	return func(l *Locker) {
+		l.watchdog = true
+		l.interval = interval
+	}
+}
+
+// WithFencing issues the fencing tokens on acquisition, the owners returned by Get implement locker.Fenced.
+// The tokens are counted in the key "{name}:token" in the same cluster slot as the lock, which never expires.
+func WithFencing() Option {
+	return func(l *Locker) {
+		l.fencing = true
+	}
+}
+
+// the defaults of the options
+const (
+	defaultTTL   = time.Second * 10
+	defaultSleep = time.Millisecond * 100
+)
+
+// minWatchdogTTL is the minimum ttl with the watchdog, so the interval is at least a millisecond.
+const minWatchdogTTL = time.Millisecond * 3
+
 var _ locker.Locker = (*Locker)(nil)
 
 func NewLocker(redis redis.UniversalClient, opts ...Option) *Locker {
 	l := &Locker{
 		redis: redis,
 		name:  uuid.New().String(),
-		ttl:   time.Second * 10,       //nolint:mnd
-		sleep: time.Millisecond * 100, //nolint:mnd
+		ttl:   defaultTTL,
+		sleep: defaultSleep,
+
+		maxSleep: time.Second,
 	}
 	for _, opt := range opts {
 		opt(l)
 	}
+
+	if l.watchdog {
+		// PEXPIRE with 0 ms deletes the lock, and the ticker panics with the 0 interval
+		l.ttl = max(l.ttl, minWatchdogTTL)
+		if l.interval <= 0 || l.interval >= l.ttl {
+			l.interval = l.ttl / 3 //nolint:mnd
+		}
+	}
+
 	return l
 }
 
+// hashTagged returns the key of the name with the suffix, which is in the same cluster slot as the name.
+// The name is hash-tagged, unless it has a hash tag already.
+func hashTagged(name, suffix string) string {
+	if start := strings.IndexByte(name, '{'); start >= 0 {
+		if end := strings.IndexByte(name[start+1:], '}'); end > 0 {
+			return name + suffix
+		}
+	}
+	return "{" + name + "}" + suffix
+}
+
 func (l *Locker) Try(ctx context.Context, fn func()) error {
 	owner, err := l.Get(ctx)
 	if err != nil {
@@ -77,25 +182,18 @@ func (l *Locker) Try(ctx context.Context, fn func()) error {
 }
 
 func (l *Locker) Until(ctx context.Context, timeout time.Duration, fn func()) error {
-	starting := time.Now()
 	owner := locker.NewOwner(l)
+	if err := l.until(ctx, timeout, owner, l.acquire); err != nil {
+		return err
+	}
 
-	for {
-		if ok, err := l.acquire(ctx, owner); err != nil {
-			return err
-		} else if ok {
-			break
-		}
-
-		if time.Since(starting) >= timeout {
-			return locker.ErrTimeout
-		}
-
-		time.Sleep(l.sleep)
+	var held locker.Owner = owner
+	if l.watchdog {
+		held = l.lease(ctx, owner)
 	}
 
 	defer func() {
-		_ = owner.Release(ctx)
+		_ = held.Release(ctx)
 	}()
 
 	fn()
@@ -105,27 +203,46 @@ func (l *Locker) Until(ctx context.Context, timeout time.Duration, fn func()) er
 
 func (l *Locker) Get(ctx context.Context) (locker.Owner, error) {
 	owner := locker.NewOwner(l)
-	ok, err := l.acquire(ctx, owner)
+	token, err := l.acquireToken(ctx, owner)
 	if err != nil {
 		return nil, err
 	}
-	if !ok {
+	if token == 0 {
 		return nil, locker.ErrLocked
 	}
+	if l.fencing {
+		owner = locker.NewOwner(l, locker.WithOwnerName(owner.Name()), locker.WithOwnerToken(token))
+	}
+	if l.watchdog {
+		return l.lease(ctx, owner), nil
+	}
 	return owner, nil
 }
 
 func (l *Locker) Release(ctx context.Context, owner locker.Owner) error {
+	if lease, ok := owner.(*Lease); ok {
+		lease.stop()
+	}
+
 	if val, err := l.redis.Eval(ctx, releaseScript, []string{l.name}, owner.Name()).Result(); err != nil {
 		return err
 	} else if val == int64(0) {
 		return locker.ErrNotLocked
 	}
+
+	l.notify(ctx)
+
 	return nil
 }
 
 func (l *Locker) ForceRelease(ctx context.Context) error {
-	return l.redis.Del(ctx, l.name).Err()
+	if err := l.redis.Del(ctx, l.name).Err(); err != nil {
+		return err
+	}
+
+	l.notify(ctx)
+
+	return nil
 }
 
 func (l *Locker) LockedOwner(ctx context.Context) (locker.Owner, error) {
@@ -140,5 +257,34 @@ func (l *Locker) LockedOwner(ctx context.Context) (locker.Owner, error) {
 }
 
 func (l *Locker) acquire(ctx context.Context, owner locker.Owner) (bool, error) {
-	return l.redis.SetNX(ctx, l.name, owner.Name(), l.ttl).Result()
+	token, err := l.acquireToken(ctx, owner)
+	return token > 0, err
+}
+
+// acquireToken acquires the lock and returns the fencing token, 0 means the lock is not acquired.
+// If the fencing is disabled, the token is always 1 on acquisition.
+func (l *Locker) acquireToken(ctx context.Context, owner locker.Owner) (int64, error) {
+	if !l.fencing {
+		ok, err := l.redis.SetNX(ctx, l.name, owner.Name(), l.ttl).Result()
+		if err != nil || !ok {
+			return 0, err
+		}
+		return 1, nil
+	}
+
+	return l.redis.Eval(ctx, fencedAcquireScript, []string{l.name, l.tokenKey()},
+		owner.Name(), l.ttl.Milliseconds(),
+	).Int64()
+}
+
+func (l *Locker) tokenKey() string {
+	return hashTagged(l.name, ":token")
+}
+
+func (l *Locker) extend(ctx context.Context, owner locker.Owner) (bool, error) {
+	val, err := l.redis.Eval(ctx, extendScript, []string{l.name}, owner.Name(), l.ttl.Milliseconds()).Result()
+	if err != nil {
+		return false, err
+	}
+	return val != int64(0), nil
 }
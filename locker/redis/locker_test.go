@@ -193,3 +193,206 @@ func TestLocker_Multi(t *testing.T) {
 
 	assert.Len(t, ch, 2)
 }
+
+func TestLocker_Watchdog(t *testing.T) {
+	l := NewLocker(newRedis(t),
+		WithName("kratos:locker:watchdog"),
+		WithTTL(time.Millisecond*300),
+		WithWatchdog(time.Millisecond*50),
+	)
+
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+	lease, ok := owner.(*Lease)
+	assert.True(t, ok)
+
+	// outlives the ttl
+	time.Sleep(time.Millisecond * 500)
+
+	locked, err := l.LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, owner.Name(), locked.Name())
+	assert.NoError(t, lease.Context().Err())
+
+	// released
+	assert.NoError(t, owner.Release(ctx))
+	assert.ErrorIs(t, lease.Context().Err(), context.Canceled)
+	assert.False(t, lease.Lost())
+
+	// the lock is not extended after the release
+	owner2, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, owner2.Release(ctx))
+}
+
+func TestLocker_Watchdog_Lost(t *testing.T) {
+	l := NewLocker(newRedis(t),
+		WithName("kratos:locker:watchdog:lost"),
+		WithTTL(time.Millisecond*300),
+		WithWatchdog(0),
+	)
+
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+	lease := owner.(*Lease)
+
+	assert.NoError(t, l.ForceRelease(ctx))
+
+	select {
+	case <-lease.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("the lease is not lost")
+	}
+	assert.True(t, lease.Lost())
+	assert.ErrorIs(t, context.Cause(lease.Context()), locker.ErrLeaseLost)
+	assert.ErrorIs(t, owner.Release(ctx), locker.ErrNotLocked)
+}
+
+func TestLocker_Watchdog_TTL(t *testing.T) {
+	l := NewLocker(newRedis(t), WithTTL(0), WithWatchdog(0))
+	assert.Equal(t, minWatchdogTTL, l.ttl)
+	assert.Equal(t, time.Millisecond, l.interval)
+
+	l = NewLocker(newRedis(t), WithTTL(time.Millisecond*300), WithWatchdog(time.Second))
+	assert.Equal(t, time.Millisecond*100, l.interval)
+
+	// the lease of the tiny ttl is kept, without panicking
+	l = NewLocker(newRedis(t),
+		WithName("kratos:locker:watchdog:ttl"),
+		WithTTL(time.Millisecond),
+		WithWatchdog(0),
+	)
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, owner.Release(ctx))
+}
+
+func TestLocker_Watchdog_Try(t *testing.T) {
+	l := NewLocker(newRedis(t),
+		WithName("kratos:locker:watchdog:try"),
+		WithTTL(time.Millisecond*100),
+		WithWatchdog(time.Millisecond*20),
+	)
+
+	ch := make(chan struct{}, 2)
+	assert.NoError(t, l.Try(ctx, func() {
+		time.Sleep(time.Millisecond * 200)
+
+		// still locked by the first one
+		assert.ErrorIs(t, l.Try(ctx, func() {
+			ch <- struct{}{}
+		}), locker.ErrLocked)
+	}))
+	assert.Len(t, ch, 0)
+
+	_, err := l.LockedOwner(ctx)
+	assert.Error(t, err)
+}
+
+func TestLocker_Until_Context(t *testing.T) {
+	l := NewLocker(newRedis(t),
+		WithName("kratos:locker:until:context"),
+	)
+
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+	defer owner.Release(ctx) //nolint:errcheck
+
+	cctx, cancel := context.WithTimeout(ctx, time.Millisecond*100)
+	defer cancel()
+
+	start := time.Now()
+	assert.ErrorIs(t, l.Until(cctx, time.Second*10, func() {}), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestLocker_Until_Notification(t *testing.T) {
+	for _, notification := range []Notification{PubSubNotification, ListNotification} {
+		// the locks with and without the ttl
+		for _, ttl := range []time.Duration{time.Second * 10, 0} {
+			l := NewLocker(newRedis(t),
+				WithName("kratos:locker:until:notification"),
+				WithTTL(ttl),
+				WithSleep(time.Second*2),
+				WithMaxSleep(time.Second*2),
+				WithNotification(notification),
+			)
+
+			owner, err := l.Get(ctx)
+			assert.NoError(t, err)
+
+			go func() {
+				time.Sleep(time.Millisecond * 100)
+				assert.NoError(t, owner.Release(ctx))
+			}()
+
+			// woken by the release, instead of the backoff
+			start := time.Now()
+			assert.NoError(t, l.Until(ctx, time.Second*5, func() {}))
+			assert.Less(t, time.Since(start), time.Millisecond*800, "%d %s", notification, ttl)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := jitter(time.Millisecond * 100)
+		assert.GreaterOrEqual(t, d, time.Millisecond*50)
+		assert.LessOrEqual(t, d, time.Millisecond*100)
+	}
+	assert.Equal(t, time.Duration(1), jitter(1))
+}
+
+func TestLocker_Fencing(t *testing.T) {
+	rdb := newRedis(t)
+	l := NewLocker(rdb,
+		WithName("kratos:locker:fencing"),
+		WithFencing(),
+	)
+	assert.Equal(t, "{kratos:locker:fencing}:token", l.tokenKey())
+	assert.NoError(t, rdb.Del(ctx, l.tokenKey()).Err())
+
+	owner1, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), locker.Token(owner1))
+
+	// no token is issued if the lock is not acquired
+	_, err = l.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	assert.NoError(t, owner1.Release(ctx))
+
+	owner2, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), locker.Token(owner2))
+	assert.NoError(t, owner2.Release(ctx))
+
+	// with the watchdog
+	l = NewLocker(rdb,
+		WithName("kratos:locker:fencing"),
+		WithFencing(),
+		WithWatchdog(0),
+	)
+	owner3, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), locker.Token(owner3))
+	assert.NoError(t, owner3.Release(ctx))
+
+	// without fencing
+	owner4, err := NewLocker(rdb, WithName("kratos:locker:fencing")).Get(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, locker.Token(owner4))
+	assert.NoError(t, owner4.Release(ctx))
+
+	// without ttl
+	l = NewLocker(rdb,
+		WithName("kratos:locker:fencing"),
+		WithFencing(),
+		WithTTL(0),
+	)
+	owner5, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), locker.Token(owner5))
+	assert.Equal(t, time.Duration(-1), rdb.PTTL(ctx, "kratos:locker:fencing").Val())
+	assert.NoError(t, owner5.Release(ctx))
+}
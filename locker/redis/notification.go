@@ -0,0 +1,168 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// Notification is the way to wake the waiters of Until when the lock is released.
+type Notification int
+
+const (
+	// NoNotification polls the lock with the backoff only.
+	NoNotification Notification = iota
+
+	// PubSubNotification publishes the release to the channel, which wakes all the waiters.
+	PubSubNotification
+
+	// ListNotification pushes the release to the list, which wakes one of the waiters.
+	ListNotification
+)
+
+// notificationTTL is the ttl of the list notification of the locks without the ttl.
+const notificationTTL = time.Minute
+
+// until tries to acquire the lock for the owner until the timeout,
+// waiting with the jittered exponential backoff or the release notification between the attempts.
+func (l *Locker) until(
+	ctx context.Context, timeout time.Duration, owner locker.Owner,
+	acquire func(context.Context, locker.Owner) (bool, error),
+) error {
+	deadline := time.Now().Add(timeout)
+	backoff := l.sleep
+
+	var w *waiter
+	defer func() {
+		w.close()
+	}()
+
+	for {
+		if ok, err := acquire(ctx, owner); err != nil {
+			return err
+		} else if ok {
+			return nil
+		}
+
+		// subscribe after the first attempt, and try again right away,
+		// so the release between the attempt and the subscription is not missed.
+		if w == nil {
+			var err error
+			if w, err = l.newWaiter(ctx); err != nil {
+				return err
+			}
+			if l.notification != NoNotification {
+				continue
+			}
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return locker.ErrTimeout
+		}
+
+		if err := w.wait(ctx, min(jitter(backoff), remaining)); err != nil {
+			return err
+		}
+
+		backoff = min(backoff*2, l.maxSleep) //nolint:mnd
+	}
+}
+
+// waiter waits for the next attempt of Until.
+type waiter struct {
+	locker *Locker
+	pubsub *redis.PubSub
+}
+
+func (l *Locker) newWaiter(ctx context.Context) (*waiter, error) {
+	w := &waiter{locker: l}
+
+	if l.notification == PubSubNotification {
+		w.pubsub = l.redis.Subscribe(ctx, l.notifyKey())
+		if _, err := w.pubsub.Receive(ctx); err != nil {
+			_ = w.pubsub.Close()
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// wait waits for the delay, the release notification or the cancellation of ctx.
+func (w *waiter) wait(ctx context.Context, delay time.Duration) error {
+	if w.locker.notification == ListNotification {
+		// the seconds of BLPOP can be a float, but 0 blocks forever
+		timeout := strconv.FormatFloat(max(delay.Seconds(), 0.001), 'f', 3, 64) //nolint:mnd
+		err := w.locker.redis.Do(ctx, "blpop", w.locker.notifyKey(), timeout).Err()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		return nil
+	}
+
+	var released <-chan *redis.Message
+	if w.pubsub != nil {
+		released = w.pubsub.Channel()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	case <-released:
+	}
+
+	return nil
+}
+
+func (w *waiter) close() {
+	if w != nil && w.pubsub != nil {
+		_ = w.pubsub.Close()
+	}
+}
+
+// notify wakes the waiters of the lock. The error is ignored,
+// since the waiters fall back to the backoff.
+func (l *Locker) notify(ctx context.Context) {
+	switch l.notification {
+	case PubSubNotification:
+		_ = l.redis.Publish(ctx, l.notifyKey(), l.name).Err()
+	case ListNotification:
+		// only one notification is kept, and it expires with the lock, or after a while without the ttl
+		ttl := l.ttl
+		if ttl <= 0 {
+			ttl = notificationTTL
+		}
+		_, _ = l.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.RPush(ctx, l.notifyKey(), l.name)
+			pipe.LTrim(ctx, l.notifyKey(), -1, -1)
+			pipe.PExpire(ctx, l.notifyKey(), ttl)
+			return nil
+		})
+	}
+}
+
+func (l *Locker) notifyKey() string {
+	return l.name + ":notify"
+}
+
+// jitter returns a random duration between the half of the backoff and the backoff.
+func jitter(backoff time.Duration) time.Duration {
+	if half := backoff / 2; half > 0 { //nolint:mnd
+		return half + time.Duration(rand.Int63n(int64(half)+1)) //nolint:gosec
+	}
+	return backoff
+}
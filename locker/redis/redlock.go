@@ -0,0 +1,270 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// clockDriftFactor is the factor of the ttl, which is reserved for the clock drift between the nodes.
+const clockDriftFactor = 0.01
+
+// Redlock is the lock acquired on the majority of the independent redis nodes, see https://redis.io/docs/latest/develop/use/patterns/distributed-locks/.
+//
+// The lock is acquired when the majority of the nodes are locked within the validity window,
+// which is the ttl minus the time elapsed to acquire and the clock drift. Otherwise, the lock
+// is released on all the nodes. The options are the same as Locker,
+// except the watchdog, the notification and the fencing, which are ignored.
+// The ttl is required by the validity window, the default ttl is used if it is not positive.
+type Redlock struct {
+	name     string
+	ttl      time.Duration
+	sleep    time.Duration // for Until, the initial backoff
+	maxSleep time.Duration // for Until, the maximum backoff
+
+	clients []redis.UniversalClient
+}
+
+// RedlockOwner is the owner of the Redlock, with the end of the validity window.
+type RedlockOwner struct {
+	locker.Owner
+
+	validUntil time.Time
+}
+
+// ValidUntil returns the end of the validity window, the lock may be held by others after it.
+func (o *RedlockOwner) ValidUntil() time.Time {
+	return o.validUntil
+}
+
+var (
+	_ locker.Locker = (*Redlock)(nil)
+	_ locker.Owner  = (*RedlockOwner)(nil)
+)
+
+func NewRedlock(clients []redis.UniversalClient, opts ...Option) *Redlock {
+	// the options are applied to the Locker, only the fields used by the Redlock are taken
+	config := &Locker{
+		name:     uuid.New().String(),
+		ttl:      defaultTTL,
+		sleep:    defaultSleep,
+		maxSleep: time.Second,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.ttl <= 0 {
+		config.ttl = defaultTTL
+	}
+
+	return &Redlock{
+		name:     config.name,
+		ttl:      config.ttl,
+		sleep:    config.sleep,
+		maxSleep: config.maxSleep,
+		clients:  clients,
+	}
+}
+
+func (r *Redlock) Try(ctx context.Context, fn func()) error {
+	owner, err := r.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (r *Redlock) Until(ctx context.Context, timeout time.Duration, fn func()) error {
+	owner := locker.NewOwner(r)
+
+	// the backoff without the notification, which never touches the redis of the Locker
+	backoff := &Locker{sleep: r.sleep, maxSleep: r.maxSleep, notification: NoNotification}
+	if err := backoff.until(ctx, timeout, owner, func(ctx context.Context, owner locker.Owner) (bool, error) {
+		_, ok, err := r.acquire(ctx, owner)
+		return ok, err
+	}); err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (r *Redlock) Get(ctx context.Context) (locker.Owner, error) {
+	owner := locker.NewOwner(r)
+	validUntil, ok, err := r.acquire(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, locker.ErrLocked
+	}
+	return &RedlockOwner{
+		Owner:      owner,
+		validUntil: validUntil,
+	}, nil
+}
+
+// Release releases the lock on all the nodes.
+// It returns locker.ErrNotLocked if the lock is not held by the owner on any node.
+func (r *Redlock) Release(ctx context.Context, owner locker.Owner) error {
+	released, errs := r.release(ctx, owner)
+	if released > 0 {
+		return nil
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return locker.ErrNotLocked
+}
+
+func (r *Redlock) ForceRelease(ctx context.Context) error {
+	return errors.Join(r.each(ctx, func(ctx context.Context, client redis.UniversalClient) error {
+		return client.Del(ctx, r.name).Err()
+	})...)
+}
+
+// LockedOwner returns the owner holding the lock on the majority of the nodes.
+func (r *Redlock) LockedOwner(ctx context.Context) (locker.Owner, error) {
+	var (
+		mu    sync.Mutex
+		votes = make(map[string]int)
+	)
+
+	errs := r.each(ctx, func(ctx context.Context, client redis.UniversalClient) error {
+		val, err := client.Get(ctx, r.name).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		votes[val]++
+		mu.Unlock()
+
+		return nil
+	})
+
+	for name, count := range votes {
+		if count >= r.quorum() {
+			return locker.NewOwner(r, locker.WithOwnerName(name)), nil
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return nil, locker.ErrNotLocked
+}
+
+// acquire acquires the lock on all the nodes, and releases it if the majority is not reached within the validity window.
+// The error is returned only if the majority can not be reached because of the errors.
+func (r *Redlock) acquire(ctx context.Context, owner locker.Owner) (time.Time, bool, error) {
+	starting := time.Now()
+
+	var (
+		mu     sync.Mutex
+		locked int
+	)
+
+	errs := r.each(ctx, func(ctx context.Context, client redis.UniversalClient) error {
+		ok, err := client.SetNX(ctx, r.name, owner.Name(), r.ttl).Result()
+		if ok {
+			mu.Lock()
+			locked++
+			mu.Unlock()
+		}
+		return err
+	})
+
+	drift := time.Duration(float64(r.ttl)*clockDriftFactor) + 2*time.Millisecond //nolint:mnd
+	validity := r.ttl - time.Since(starting) - drift
+
+	if locked >= r.quorum() && validity > 0 {
+		return starting.Add(validity), true, nil
+	}
+
+	// release the partially acquired lock with a new context, so it is not left until the ttl
+	_, _ = r.release(context.WithoutCancel(ctx), owner)
+
+	if len(r.clients)-len(errs) < r.quorum() {
+		return time.Time{}, false, errors.Join(errs...)
+	}
+	return time.Time{}, false, nil
+}
+
+func (r *Redlock) release(ctx context.Context, owner locker.Owner) (int, []error) {
+	var (
+		mu       sync.Mutex
+		released int
+	)
+
+	errs := r.each(ctx, func(ctx context.Context, client redis.UniversalClient) error {
+		val, err := client.Eval(ctx, releaseScript, []string{r.name}, owner.Name()).Result()
+		if err != nil {
+			return err
+		}
+		if val != int64(0) {
+			mu.Lock()
+			released++
+			mu.Unlock()
+		}
+		return nil
+	})
+
+	return released, errs
+}
+
+// each calls fn with all the nodes concurrently, and each call is limited by a small part of the ttl,
+// so an unavailable node does not eat the validity window. It returns the errors of the nodes.
+func (r *Redlock) each(ctx context.Context, fn func(context.Context, redis.UniversalClient) error) []error {
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, client := range r.clients {
+		wg.Add(1)
+		go func(client redis.UniversalClient) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, r.nodeTimeout())
+			defer cancel()
+
+			if err := fn(ctx, client); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(client)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+func (r *Redlock) nodeTimeout() time.Duration {
+	return max(r.ttl/10, time.Millisecond*50) //nolint:mnd
+}
+
+func (r *Redlock) quorum() int {
+	return len(r.clients)/2 + 1 //nolint:mnd
+}
@@ -0,0 +1,136 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// newNodes returns the clients of the databases, which act as the independent nodes.
+func newNodes(t *testing.T, n int) []redis.UniversalClient {
+	nodes := make([]redis.UniversalClient, 0, n)
+	for i := 0; i < n; i++ {
+		rdb := redis.NewClient(&redis.Options{
+			Addr: "localhost:6379",
+			DB:   i + 1,
+		})
+		t.Cleanup(func() {
+			_ = rdb.FlushDB(ctx).Err()
+			_ = rdb.Close()
+		})
+		nodes = append(nodes, rdb)
+	}
+	return nodes
+}
+
+func TestRedlock(t *testing.T) {
+	nodes := newNodes(t, 3)
+	l := NewRedlock(nodes,
+		WithName("kratos:locker:redlock"),
+		WithTTL(time.Second),
+	)
+
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.True(t, owner.(*RedlockOwner).ValidUntil().After(time.Now()))
+	assert.True(t, owner.(*RedlockOwner).ValidUntil().Before(time.Now().Add(time.Second)))
+
+	for _, node := range nodes {
+		assert.Equal(t, owner.Name(), node.Get(ctx, "kratos:locker:redlock").Val())
+	}
+
+	locked, err := l.LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, owner.Name(), locked.Name())
+
+	_, err = l.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	assert.ErrorIs(t, l.Release(ctx, locker.NewOwner(l)), locker.ErrNotLocked)
+	assert.NoError(t, owner.Release(ctx))
+
+	for _, node := range nodes {
+		assert.Zero(t, node.Exists(ctx, "kratos:locker:redlock").Val())
+	}
+
+	_, err = l.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+}
+
+func TestRedlock_Quorum(t *testing.T) {
+	nodes := newNodes(t, 3)
+	l := NewRedlock(nodes,
+		WithName("kratos:locker:redlock:quorum"),
+		WithTTL(time.Second),
+	)
+
+	// the minority is locked by others
+	assert.NoError(t, nodes[0].Set(ctx, "kratos:locker:redlock:quorum", "other", time.Second).Err())
+
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, owner.Release(ctx))
+	assert.Equal(t, "other", nodes[0].Get(ctx, "kratos:locker:redlock:quorum").Val())
+
+	// the majority is locked by others, the partially acquired lock is released
+	assert.NoError(t, nodes[1].Set(ctx, "kratos:locker:redlock:quorum", "other", time.Second).Err())
+
+	_, err = l.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+	assert.Zero(t, nodes[2].Exists(ctx, "kratos:locker:redlock:quorum").Val())
+
+	locked, err := l.LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "other", locked.Name())
+
+	assert.NoError(t, l.ForceRelease(ctx))
+	assert.NoError(t, l.Try(ctx, func() {}))
+}
+
+func TestRedlock_Unavailable(t *testing.T) {
+	nodes := newNodes(t, 2)
+	down := redis.NewClient(&redis.Options{Addr: "localhost:1"})
+	t.Cleanup(func() {
+		_ = down.Close()
+	})
+
+	// the majority of 3 nodes is available
+	l := NewRedlock(append(nodes, down),
+		WithName("kratos:locker:redlock:unavailable"),
+		WithTTL(time.Second),
+	)
+	assert.NoError(t, l.Try(ctx, func() {}))
+	assert.NoError(t, l.Until(ctx, time.Second, func() {}))
+
+	// the majority of 3 nodes is unavailable
+	l = NewRedlock([]redis.UniversalClient{nodes[0], down, down},
+		WithName("kratos:locker:redlock:unavailable"),
+		WithTTL(time.Second),
+	)
+	_, err := l.Get(ctx)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, locker.ErrLocked)
+	assert.Zero(t, nodes[0].Exists(ctx, "kratos:locker:redlock:unavailable").Val())
+}
+
+func TestRedlock_NoTTL(t *testing.T) {
+	nodes := newNodes(t, 3)
+
+	// the default ttl is used, so the lock can be acquired
+	l := NewRedlock(nodes,
+		WithName("kratos:locker:redlock:nottl"),
+		WithTTL(0),
+	)
+	assert.Equal(t, defaultTTL, l.ttl)
+
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.True(t, owner.(*RedlockOwner).ValidUntil().After(time.Now()))
+	assert.NoError(t, owner.Release(ctx))
+
+	assert.NoError(t, l.Until(ctx, time.Second, func() {}))
+}
@@ -0,0 +1,173 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// reentrantAcquireScript is a Lua script to acquire or reacquire a reentrant lock in an atomic way.
+//
+//	KEYS[1] is the lock key, a hash of the owner and the hold count
+//	ARGV[1] is the owner
+//	ARGV[2] is the ttl in milliseconds, the lock never expires if it is not positive
+const reentrantAcquireScript = `if redis.call("exists",KEYS[1]) == 0 or redis.call("hexists",KEYS[1],ARGV[1]) == 1 then
+    local count = redis.call("hincrby",KEYS[1],ARGV[1],1)
+    if tonumber(ARGV[2]) > 0 then
+        redis.call("pexpire",KEYS[1],ARGV[2])
+    end
+    return count
+end
+return 0`
+
+// reentrantReleaseScript is a Lua script to release a reentrant lock in an atomic way,
+// the lock is deleted when the hold count reaches zero.
+//
+//	KEYS[1] is the lock key
+//	ARGV[1] is the owner
+//	ARGV[2] is the ttl in milliseconds, the lock never expires if it is not positive
+const reentrantReleaseScript = `if redis.call("hexists",KEYS[1],ARGV[1]) == 0 then
+    return -1
+end
+local count = redis.call("hincrby",KEYS[1],ARGV[1],-1)
+if count <= 0 then
+    redis.call("del",KEYS[1])
+    return 0
+end
+if tonumber(ARGV[2]) > 0 then
+    redis.call("pexpire",KEYS[1],ARGV[2])
+end
+return count`
+
+// ReentrantLocker is the lock, which can be reacquired by the owner holding it.
+//
+// The owner is the one carried in the context by locker.NewContext, or the one passed to Acquire.
+// Each acquisition increases the hold count, and the lock is released when the count reaches zero.
+// The options are the same as Locker, except the watchdog and the fencing, which are ignored.
+type ReentrantLocker struct {
+	*Locker
+}
+
+var _ locker.Locker = (*ReentrantLocker)(nil)
+
+func NewReentrantLocker(redis redis.UniversalClient, opts ...Option) *ReentrantLocker {
+	config := NewLocker(redis, opts...)
+	config.watchdog = false
+	config.fencing = false
+
+	return &ReentrantLocker{
+		Locker: config,
+	}
+}
+
+func (l *ReentrantLocker) Try(ctx context.Context, fn func()) error {
+	owner, err := l.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (l *ReentrantLocker) Until(ctx context.Context, timeout time.Duration, fn func()) error {
+	owner := l.owner(ctx)
+	if err := l.until(ctx, timeout, owner, l.acquire); err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+// Get acquires the lock with the owner in the context, or a new owner if there is none.
+func (l *ReentrantLocker) Get(ctx context.Context) (locker.Owner, error) {
+	owner := l.owner(ctx)
+	if err := l.Acquire(ctx, owner); err != nil {
+		return nil, err
+	}
+	return owner, nil
+}
+
+// Acquire acquires the lock with the owner, it succeeds if the owner is holding the lock.
+// Each successful call must be paired with a release.
+func (l *ReentrantLocker) Acquire(ctx context.Context, owner locker.Owner) error {
+	ok, err := l.acquire(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return locker.ErrLocked
+	}
+	return nil
+}
+
+// Release decreases the hold count of the owner, and releases the lock when the count reaches zero.
+func (l *ReentrantLocker) Release(ctx context.Context, owner locker.Owner) error {
+	val, err := l.redis.Eval(ctx, reentrantReleaseScript, []string{l.name},
+		owner.Name(), l.ttl.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case val < 0:
+		return locker.ErrNotLocked
+	case val == 0:
+		l.notify(ctx)
+	}
+
+	return nil
+}
+
+func (l *ReentrantLocker) LockedOwner(ctx context.Context) (locker.Owner, error) {
+	names, err := l.redis.HKeys(ctx, l.name).Result()
+	if err != nil {
+		return nil, err
+	} else if len(names) == 0 {
+		return nil, locker.ErrNotLocked
+	}
+
+	return locker.NewOwner(l, locker.WithOwnerName(names[0])), nil
+}
+
+// HoldCount returns the hold count of the owner, 0 means the owner does not hold the lock.
+func (l *ReentrantLocker) HoldCount(ctx context.Context, owner locker.Owner) (int, error) {
+	count, err := l.redis.HGet(ctx, l.name, owner.Name()).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// owner returns the owner bound to the locker, with the name of the owner in the context.
+func (l *ReentrantLocker) owner(ctx context.Context) locker.Owner {
+	if owner, ok := locker.FromContext(ctx); ok {
+		return locker.NewOwner(l, locker.WithOwnerName(owner.Name()))
+	}
+	return locker.NewOwner(l)
+}
+
+func (l *ReentrantLocker) acquire(ctx context.Context, owner locker.Owner) (bool, error) {
+	val, err := l.redis.Eval(ctx, reentrantAcquireScript, []string{l.name},
+		owner.Name(), l.ttl.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return false, err
+	}
+
+	return val > 0, nil
+}
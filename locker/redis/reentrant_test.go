@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+func TestReentrantLocker(t *testing.T) {
+	l := NewReentrantLocker(newRedis(t),
+		WithName("kratos:locker:reentrant"),
+		WithTTL(time.Second),
+	)
+	assert.NoError(t, l.ForceRelease(ctx))
+
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+
+	// the others can not acquire
+	_, err = l.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+	assert.ErrorIs(t, l.Try(ctx, func() {}), locker.ErrLocked)
+
+	// reacquire with the owner in the context
+	octx := locker.NewContext(ctx, owner)
+	var nested bool
+	assert.NoError(t, l.Try(octx, func() {
+		assert.NoError(t, l.Until(octx, time.Second, func() {
+			count, err := l.HoldCount(ctx, owner)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, count)
+			nested = true
+		}))
+	}))
+	assert.True(t, nested)
+
+	// reacquire with the owner
+	assert.NoError(t, l.Acquire(ctx, owner))
+
+	count, err := l.HoldCount(ctx, owner)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	locked, err := l.LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, owner.Name(), locked.Name())
+
+	// released when the count reaches zero
+	assert.NoError(t, owner.Release(ctx))
+	_, err = l.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	assert.NoError(t, owner.Release(ctx))
+	assert.ErrorIs(t, owner.Release(ctx), locker.ErrNotLocked)
+
+	count, err = l.HoldCount(ctx, owner)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	_, err = l.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+
+	owner2, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, owner2.Release(ctx))
+}
+
+func TestReentrantLocker_Expired(t *testing.T) {
+	l := NewReentrantLocker(newRedis(t),
+		WithName("kratos:locker:reentrant:expired"),
+		WithTTL(time.Millisecond*100),
+	)
+
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, l.Acquire(ctx, owner))
+
+	time.Sleep(time.Millisecond * 150)
+
+	assert.NoError(t, l.Try(ctx, func() {}))
+	assert.ErrorIs(t, owner.Release(ctx), locker.ErrNotLocked)
+}
+
+func TestReentrantLocker_NoTTL(t *testing.T) {
+	client := newRedis(t)
+	l := NewReentrantLocker(client,
+		WithName("kratos:locker:reentrant:nottl"),
+		WithTTL(0),
+	)
+	assert.NoError(t, l.ForceRelease(ctx))
+
+	// the lock is kept without the ttl
+	owner, err := l.Get(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, l.Acquire(ctx, owner))
+	assert.Equal(t, time.Duration(-1), client.PTTL(ctx, l.name).Val())
+
+	assert.NoError(t, owner.Release(ctx))
+	count, err := l.HoldCount(ctx, owner)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	assert.NoError(t, owner.Release(ctx))
+	_, err = l.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+}
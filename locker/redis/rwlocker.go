@@ -0,0 +1,130 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// writeScript is a Lua script to acquire the write lock in an atomic way.
+//
+//	KEYS[1] is the writer key
+//	KEYS[2] is the readers key
+//	ARGV[1] is the owner
+//	ARGV[2] is the ttl in milliseconds, the lock never expires if it is not positive
+const writeScript = nowScript + `if redis.call("exists",KEYS[1]) == 1 then
+    return 0
+end
+redis.call("zremrangebyscore",KEYS[2],"-inf",now)
+if redis.call("zcard",KEYS[2]) > 0 then
+    return 0
+end
+if tonumber(ARGV[2]) > 0 then
+    redis.call("set",KEYS[1],ARGV[1],"px",ARGV[2])
+else
+    redis.call("set",KEYS[1],ARGV[1])
+end
+return 1`
+
+// RWLocker is the shared/exclusive lock, like sync.RWMutex.
+//
+// The RWLocker itself is the exclusive (write) lock, and RLocker returns the shared (read) lock.
+// The writer is kept in the key "{name}:writer", and the readers are kept in the sorted set "{name}:readers",
+// each of them expires after the ttl. The keys are in the same cluster slot, the name is not hash-tagged
+// if it has a hash tag already. The writers are not preferred to the readers.
+// The options are the same as Locker, except the watchdog and the fencing, which are ignored.
+type RWLocker struct {
+	*Locker
+
+	readers *slots
+}
+
+var _ locker.Locker = (*RWLocker)(nil)
+
+func NewRWLocker(redis redis.UniversalClient, opts ...Option) *RWLocker {
+	config := NewLocker(redis, opts...)
+	config.watchdog = false
+	config.fencing = false
+
+	name := config.name
+	config.name = hashTagged(name, ":writer")
+
+	return &RWLocker{
+		Locker: config,
+		readers: &slots{
+			config:  config,
+			key:     hashTagged(name, ":readers"),
+			blocker: config.name,
+		},
+	}
+}
+
+// RLocker returns the shared lock, which is held by many readers unless the writer holds the lock.
+func (rw *RWLocker) RLocker() locker.Locker {
+	return rw.readers
+}
+
+func (rw *RWLocker) Try(ctx context.Context, fn func()) error {
+	owner, err := rw.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (rw *RWLocker) Until(ctx context.Context, timeout time.Duration, fn func()) error {
+	owner := locker.NewOwner(rw)
+	if err := rw.until(ctx, timeout, owner, rw.acquire); err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (rw *RWLocker) Get(ctx context.Context) (locker.Owner, error) {
+	owner := locker.NewOwner(rw)
+	ok, err := rw.acquire(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, locker.ErrLocked
+	}
+	return owner, nil
+}
+
+func (rw *RWLocker) LockedOwner(ctx context.Context) (locker.Owner, error) {
+	val, err := rw.redis.Get(ctx, rw.name).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, locker.ErrNotLocked
+	} else if err != nil {
+		return nil, err
+	}
+	return locker.NewOwner(rw, locker.WithOwnerName(val)), nil
+}
+
+func (rw *RWLocker) acquire(ctx context.Context, owner locker.Owner) (bool, error) {
+	val, err := rw.redis.Eval(ctx, writeScript, []string{rw.name, rw.readers.key},
+		owner.Name(), rw.ttl.Milliseconds(),
+	).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return val != int64(0), nil
+}
@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+func TestRWLocker(t *testing.T) {
+	rw := NewRWLocker(newRedis(t),
+		WithName("kratos:locker:rw"),
+		WithTTL(time.Second),
+	)
+	assert.NoError(t, rw.ForceRelease(ctx))
+	assert.NoError(t, rw.RLocker().ForceRelease(ctx))
+
+	// many readers
+	reader1, err := rw.RLocker().Get(ctx)
+	assert.NoError(t, err)
+	reader2, err := rw.RLocker().Get(ctx)
+	assert.NoError(t, err)
+
+	owner, err := rw.RLocker().LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, []string{reader1.Name(), reader2.Name()}, owner.Name())
+
+	// the writer waits for the readers
+	_, err = rw.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	assert.NoError(t, reader1.Release(ctx))
+	assert.ErrorIs(t, reader1.Release(ctx), locker.ErrNotLocked)
+	assert.NoError(t, reader2.Release(ctx))
+
+	// the readers wait for the writer
+	writer, err := rw.Get(ctx)
+	assert.NoError(t, err)
+
+	owner, err = rw.LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, writer.Name(), owner.Name())
+
+	_, err = rw.RLocker().Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+	_, err = rw.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	assert.ErrorIs(t, rw.Release(ctx, locker.NewOwner(rw)), locker.ErrNotLocked)
+	assert.NoError(t, writer.Release(ctx))
+
+	_, err = rw.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+	_, err = rw.RLocker().LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+}
+
+func TestRWLocker_Until(t *testing.T) {
+	rw := NewRWLocker(newRedis(t),
+		WithName("kratos:locker:rw:until"),
+		WithTTL(time.Second),
+		WithSleep(time.Millisecond*10),
+	)
+
+	reader, err := rw.RLocker().Get(ctx)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, rw.Until(ctx, time.Millisecond*50, func() {}), locker.ErrTimeout)
+
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		assert.NoError(t, reader.Release(ctx))
+	}()
+
+	var called bool
+	assert.NoError(t, rw.Until(ctx, time.Second, func() {
+		called = true
+	}))
+	assert.True(t, called)
+
+	assert.NoError(t, rw.RLocker().Try(ctx, func() {}))
+	assert.NoError(t, rw.RLocker().Until(ctx, time.Second, func() {}))
+	assert.NoError(t, rw.Try(ctx, func() {}))
+}
+
+func TestRWLocker_Expired(t *testing.T) {
+	rw := NewRWLocker(newRedis(t),
+		WithName("kratos:locker:rw:expired"),
+		WithTTL(time.Millisecond*100),
+	)
+
+	// the crashed reader
+	_, err := rw.RLocker().Get(ctx)
+	assert.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 150)
+
+	writer, err := rw.Get(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Release(ctx))
+}
+
+func TestRWLocker_Keys(t *testing.T) {
+	rw := NewRWLocker(newRedis(t), WithName("kratos:locker:rw:keys"))
+
+	// hash-tagged in the same cluster slot
+	assert.Equal(t, "{kratos:locker:rw:keys}:writer", rw.name)
+	assert.Equal(t, "{kratos:locker:rw:keys}:readers", rw.readers.key)
+
+	// the hash tag of the name is kept
+	rw = NewRWLocker(newRedis(t), WithName("{kratos}:locker:rw:keys"))
+	assert.Equal(t, "{kratos}:locker:rw:keys:writer", rw.name)
+	assert.Equal(t, "{kratos}:locker:rw:keys:readers", rw.readers.key)
+}
+
+func TestRWLocker_NoTTL(t *testing.T) {
+	client := newRedis(t)
+	rw := NewRWLocker(client,
+		WithName("kratos:locker:rw:nottl"),
+		WithTTL(0),
+	)
+	assert.NoError(t, rw.ForceRelease(ctx))
+	assert.NoError(t, rw.RLocker().ForceRelease(ctx))
+
+	// the writer never expires
+	writer, err := rw.Get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(-1), client.PTTL(ctx, rw.name).Val())
+	assert.NoError(t, writer.Release(ctx))
+
+	// the readers never expire
+	reader, err := rw.RLocker().Get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(-1), client.PTTL(ctx, rw.readers.key).Val())
+
+	owner, err := rw.RLocker().LockedOwner(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, reader.Name(), owner.Name())
+
+	_, err = rw.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	assert.NoError(t, reader.Release(ctx))
+	assert.ErrorIs(t, reader.Release(ctx), locker.ErrNotLocked)
+}
@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// Semaphore is the counting semaphore, which allows at most limit owners at the same time,
+// e.g. at most 3 workers process the tasks of a tenant.
+//
+// Each owner expires after the ttl, so the slots of the crashed owners are reclaimed.
+// The options are the same as Locker, except the watchdog and the fencing, which are ignored.
+type Semaphore struct {
+	*slots
+}
+
+var _ locker.Locker = (*Semaphore)(nil)
+
+func NewSemaphore(redis redis.UniversalClient, limit int, opts ...Option) *Semaphore {
+	config := NewLocker(redis, opts...)
+	config.watchdog = false
+	config.fencing = false
+
+	return &Semaphore{
+		slots: &slots{
+			config: config,
+			key:    config.name,
+			limit:  max(limit, 1),
+		},
+	}
+}
+
+// Count returns the number of the current owners.
+func (s *Semaphore) Count(ctx context.Context) (int, error) {
+	holders, err := s.holders(ctx)
+	return len(holders), err
+}
@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+func TestSemaphore(t *testing.T) {
+	s := NewSemaphore(newRedis(t), 2,
+		WithName("kratos:locker:semaphore"),
+		WithTTL(time.Second),
+	)
+	assert.NoError(t, s.ForceRelease(ctx))
+
+	owner1, err := s.Get(ctx)
+	assert.NoError(t, err)
+	owner2, err := s.Get(ctx)
+	assert.NoError(t, err)
+
+	_, err = s.Get(ctx)
+	assert.ErrorIs(t, err, locker.ErrLocked)
+
+	count, err := s.Count(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	assert.NoError(t, owner1.Release(ctx))
+	assert.ErrorIs(t, owner1.Release(ctx), locker.ErrNotLocked)
+
+	owner3, err := s.Get(ctx)
+	assert.NoError(t, err)
+
+	assert.NoError(t, owner2.Release(ctx))
+	assert.NoError(t, owner3.Release(ctx))
+
+	count, err = s.Count(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	_, err = s.LockedOwner(ctx)
+	assert.ErrorIs(t, err, locker.ErrNotLocked)
+}
+
+func TestSemaphore_Concurrent(t *testing.T) {
+	s := NewSemaphore(newRedis(t), 3,
+		WithName("kratos:locker:semaphore:concurrent"),
+		WithSleep(time.Millisecond*10),
+		WithMaxSleep(time.Millisecond*20),
+	)
+	assert.NoError(t, s.ForceRelease(ctx))
+
+	var running, peak int64
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, s.Until(ctx, time.Second*5, func() {
+				n := atomic.AddInt64(&running, 1)
+				for {
+					p := atomic.LoadInt64(&peak)
+					if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond * 30)
+				atomic.AddInt64(&running, -1)
+			}))
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak, int64(3))
+	assert.Greater(t, peak, int64(1))
+}
+
+func TestSemaphore_Expired(t *testing.T) {
+	s := NewSemaphore(newRedis(t), 1,
+		WithName("kratos:locker:semaphore:expired"),
+		WithTTL(time.Millisecond*100),
+	)
+
+	// the crashed owner
+	owner, err := s.Get(ctx)
+	assert.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 150)
+
+	assert.NoError(t, s.Try(ctx, func() {}))
+	assert.ErrorIs(t, owner.Release(ctx), locker.ErrNotLocked)
+}
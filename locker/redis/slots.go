@@ -0,0 +1,165 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kratos-ecosystem/components/v2/locker"
+)
+
+// nowScript is the Lua snippet to get the current time of redis in milliseconds,
+// so the expiration of the holders does not depend on the clocks of the clients.
+const nowScript = `local t = redis.call("time")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+`
+
+// acquireSlotScript is a Lua script to acquire a slot in an atomic way.
+// The holders are kept in a sorted set scored by their expiration time.
+//
+//	KEYS[1] is the holders key
+//	KEYS[2] is the optional key, which blocks the acquiring while it exists
+//	ARGV[1] is the owner
+//	ARGV[2] is the ttl in milliseconds, the holder never expires if it is not positive
+//	ARGV[3] is the limit of the holders, 0 means unlimited
+const acquireSlotScript = nowScript + `if KEYS[2] and redis.call("exists",KEYS[2]) == 1 then
+    return 0
+end
+redis.call("zremrangebyscore",KEYS[1],"-inf",now)
+local limit = tonumber(ARGV[3])
+if limit > 0 and redis.call("zcard",KEYS[1]) >= limit then
+    return 0
+end
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+    redis.call("zadd",KEYS[1],"+inf",ARGV[1])
+    redis.call("persist",KEYS[1])
+    return 1
+end
+local pttl = redis.call("pttl",KEYS[1])
+redis.call("zadd",KEYS[1],now+ttl,ARGV[1])
+-- the new set expires with the holder, and the set of the holders never expiring is kept
+if pttl == -2 or pttl >= 0 and pttl < ttl then
+    redis.call("pexpire",KEYS[1],ttl)
+end
+return 1`
+
+// releaseSlotScript is a Lua script to release a slot in an atomic way.
+//
+//	KEYS[1] is the holders key
+//	ARGV[1] is the owner
+const releaseSlotScript = nowScript + `local score = redis.call("zscore",KEYS[1],ARGV[1])
+redis.call("zrem",KEYS[1],ARGV[1])
+if score == "inf" or score and tonumber(score) > now then
+    return 1
+end
+return 0`
+
+// holdersScript is a Lua script to get the unexpired holders.
+//
+//	KEYS[1] is the holders key
+const holdersScript = nowScript + `return redis.call("zrangebyscore",KEYS[1],"(" .. now,"+inf")`
+
+// slots is the locker, which allows the limited holders at the same time.
+type slots struct {
+	config  *Locker
+	key     string
+	blocker string // optional
+	limit   int
+}
+
+var _ locker.Locker = (*slots)(nil)
+
+func (s *slots) Try(ctx context.Context, fn func()) error {
+	owner, err := s.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (s *slots) Until(ctx context.Context, timeout time.Duration, fn func()) error {
+	owner := locker.NewOwner(s)
+	if err := s.config.until(ctx, timeout, owner, s.acquire); err != nil {
+		return err
+	}
+	defer func() {
+		_ = owner.Release(ctx)
+	}()
+
+	fn()
+
+	return nil
+}
+
+func (s *slots) Get(ctx context.Context) (locker.Owner, error) {
+	owner := locker.NewOwner(s)
+	ok, err := s.acquire(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, locker.ErrLocked
+	}
+	return owner, nil
+}
+
+func (s *slots) Release(ctx context.Context, owner locker.Owner) error {
+	if val, err := s.config.redis.Eval(ctx, releaseSlotScript, []string{s.key}, owner.Name()).Result(); err != nil {
+		return err
+	} else if val == int64(0) {
+		return locker.ErrNotLocked
+	}
+
+	s.config.notify(ctx)
+
+	return nil
+}
+
+// ForceRelease releases all the holders forcibly.
+func (s *slots) ForceRelease(ctx context.Context) error {
+	if err := s.config.redis.Del(ctx, s.key).Err(); err != nil {
+		return err
+	}
+
+	s.config.notify(ctx)
+
+	return nil
+}
+
+// LockedOwner returns the earliest expiring holder.
+func (s *slots) LockedOwner(ctx context.Context) (locker.Owner, error) {
+	holders, err := s.holders(ctx)
+	if err != nil {
+		return nil, err
+	} else if len(holders) == 0 {
+		return nil, locker.ErrNotLocked
+	}
+
+	return locker.NewOwner(s, locker.WithOwnerName(holders[0])), nil
+}
+
+func (s *slots) holders(ctx context.Context) ([]string, error) {
+	return s.config.redis.Eval(ctx, holdersScript, []string{s.key}).StringSlice()
+}
+
+func (s *slots) acquire(ctx context.Context, owner locker.Owner) (bool, error) {
+	keys := []string{s.key}
+	if s.blocker != "" {
+		keys = append(keys, s.blocker)
+	}
+
+	val, err := s.config.redis.Eval(ctx, acquireSlotScript, keys,
+		owner.Name(), s.config.ttl.Milliseconds(), s.limit,
+	).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return val != int64(0), nil
+}